@@ -0,0 +1,436 @@
+package djson
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"strconv"
+)
+
+const (
+	AGG_COUNT = iota
+	AGG_SUM
+	AGG_AVG
+	AGG_MIN
+	AGG_MAX
+)
+
+var aggNames = map[int]string{
+	AGG_COUNT: "count",
+	AGG_SUM:   "sum",
+	AGG_AVG:   "avg",
+	AGG_MIN:   "min",
+	AGG_MAX:   "max",
+}
+
+// Aggregate computes Op over Field of the rows of a group and stores it
+// under As, by default "<op>_<field>" or "count". Nulls are ignored;
+// AGG_COUNT without Field counts rows. AGG_SUM and AGG_AVG use numbers
+// only, AGG_MIN and AGG_MAX compare any values as SortBy does. Fields
+// may be paths such as "user.age".
+
+type Aggregate struct {
+	Op    int
+	Field string
+	As    string
+}
+
+// SortKey orders rows by Field. Nulls and missing fields come last unless
+// NullsFirst is set, whatever the direction.
+
+type SortKey struct {
+	Field      string
+	Desc       bool
+	NullsFirst bool
+}
+
+// fieldValue resolves field as a key of Flatten, such as "user.age" or
+// "items[0].id".
+
+func fieldValue(row interface{}, field string) interface{} {
+	if field == "" {
+		return row
+	}
+
+	f, _ := newFlattener(nil)
+	tokens, err := f.tokens(field)
+	if err != nil {
+		return nil
+	}
+
+	v, failed := getPathElement(row, tokens)
+	if failed >= 0 {
+		return nil
+	}
+	return v
+}
+
+// canonicalKey returns the same key for equal values: objects whatever
+// their key order and numbers by exact value, so that 1 equals 1.0 but
+// 9007199254740993 does not equal 9007199254740992. Numbers are written
+// as 0.<digits>e<exp>, never through float64, and strings quoted.
+
+func canonicalKey(element interface{}) string {
+	var buf bytes.Buffer
+	writeCanonicalKey(&buf, element)
+	return buf.String()
+}
+
+func writeCanonicalKey(buf *bytes.Buffer, element interface{}) {
+	switch t := element.(type) {
+	case DO:
+		writeCanonicalKey(buf, &t)
+		return
+	case DA:
+		writeCanonicalKey(buf, &t)
+		return
+	case *DO:
+		if t == nil {
+			buf.WriteString("null")
+			return
+		}
+
+		keys := t.Keys()
+		sort.Strings(keys)
+
+		buf.WriteByte('{')
+		for idx, k := range keys {
+			if idx > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, k)
+			buf.WriteByte(':')
+			writeCanonicalKey(buf, t.Map[k])
+		}
+		buf.WriteByte('}')
+		return
+	case *DA:
+		if t == nil {
+			buf.WriteString("null")
+			return
+		}
+
+		buf.WriteByte('[')
+		for idx := range t.Element {
+			if idx > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalKey(buf, t.Element[idx])
+		}
+		buf.WriteByte(']')
+		return
+	}
+
+	if isNumberElement(element) {
+		d, ok := decimalOf(element)
+		switch {
+		case !ok:
+			f, _ := getFloatBase(element)
+			buf.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+		case d.digits == "":
+			buf.WriteByte('0')
+		default:
+			if d.neg {
+				buf.WriteByte('-')
+			}
+			buf.WriteString("0.")
+			buf.WriteString(d.digits)
+			buf.WriteByte('e')
+			buf.WriteString(strconv.Itoa(d.exp))
+		}
+		return
+	}
+
+	if err := writeCanonical(buf, element); err != nil {
+		buf.WriteString(encodeElement(element))
+	}
+}
+
+func isNumberElement(element interface{}) bool {
+	t := elementType(element)
+	return t == JSON_INT || t == JSON_FLOAT
+}
+
+func elementRank(element interface{}) int {
+	switch elementType(element) {
+	case JSON_INT, JSON_FLOAT:
+		return 1
+	case JSON_STRING:
+		return 2
+	case JSON_BOOL:
+		return 3
+	case JSON_OBJECT:
+		return 4
+	case JSON_ARRAY:
+		return 5
+	}
+	return 0
+}
+
+// compareElements orders numbers by value, strings as compareString and
+// false before true. Values of different types order as number, string,
+// bool, object, array; objects and arrays compare equal among themselves.
+
+func compareElements(a, b interface{}) int {
+	ra, rb := elementRank(a), elementRank(b)
+	if ra != rb {
+		return ra - rb
+	}
+
+	switch ra {
+	case 1:
+		ai, aok := a.(int64)
+		bi, bok := b.(int64)
+		if aok && bok {
+			switch {
+			case ai < bi:
+				return -1
+			case ai > bi:
+				return 1
+			}
+			return 0
+		}
+
+		ad, aok := decimalOf(a)
+		bd, bok := decimalOf(b)
+		if aok && bok {
+			return compareDecimal(ad, bd)
+		}
+
+		af, _ := getFloatBase(a)
+		bf, _ := getFloatBase(b)
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+	case 2:
+		as, _ := a.(string)
+		bs, _ := b.(string)
+		return compareString(as, bs)
+	case 3:
+		ab, _ := a.(bool)
+		bb, _ := b.(bool)
+		switch {
+		case !ab && bb:
+			return -1
+		case ab && !bb:
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// SortBy returns a copy of m sorted by keys, earlier keys first. The sort
+// is stable.
+
+func (m *DA) SortBy(keys ...*SortKey) *DA {
+	ret := m.Clone()
+
+	sort.SliceStable(ret.Element, func(i, j int) bool {
+		for _, key := range keys {
+			a := fieldValue(ret.Element[i], key.Field)
+			b := fieldValue(ret.Element[j], key.Field)
+
+			if a == nil || b == nil {
+				if a == nil && b == nil {
+					continue
+				}
+				return (a == nil) == key.NullsFirst
+			}
+
+			c := compareElements(a, b)
+			if c == 0 {
+				continue
+			}
+			if key.Desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+
+	return ret
+}
+
+// Distinct returns the distinct values of field in the order first seen.
+// An empty field takes the elements themselves. Missing fields count as
+// null.
+
+func (m *DA) Distinct(field string) *DA {
+	ret := NewArray()
+	seen := make(map[string]bool)
+
+	for idx := range m.Element {
+		v := fieldValue(m.Element[idx], field)
+
+		key := canonicalKey(v)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		ret.Element = append(ret.Element, cloneElement(v))
+	}
+
+	return ret
+}
+
+type aggState struct {
+	count    int64
+	numbers  int64
+	allInt   bool
+	sumInt   int64
+	sumFloat float64
+	best     interface{}
+}
+
+func (s *aggState) add(op int, v interface{}) {
+	s.count++
+
+	switch op {
+	case AGG_SUM, AGG_AVG:
+		if !isNumberElement(v) {
+			return
+		}
+		s.numbers++
+
+		f, _ := getFloatBase(v)
+		s.sumFloat += f
+
+		if i, ok := getIntBase(v); ok && s.allInt && elementType(v) == JSON_INT {
+			if (i > 0 && s.sumInt > math.MaxInt64-i) || (i < 0 && s.sumInt < math.MinInt64-i) {
+				s.allInt = false
+			} else {
+				s.sumInt += i
+			}
+		} else {
+			s.allInt = false
+		}
+	case AGG_MIN:
+		if s.best == nil || compareElements(v, s.best) < 0 {
+			s.best = v
+		}
+	case AGG_MAX:
+		if s.best == nil || compareElements(v, s.best) > 0 {
+			s.best = v
+		}
+	}
+}
+
+func (s *aggState) result(op int) interface{} {
+	switch op {
+	case AGG_COUNT:
+		return s.count
+	case AGG_SUM:
+		if s.allInt {
+			return s.sumInt
+		}
+		return s.sumFloat
+	case AGG_AVG:
+		if s.numbers == 0 {
+			return nil
+		}
+		return s.sumFloat / float64(s.numbers)
+	}
+
+	return cloneElement(s.best)
+}
+
+func (a *Aggregate) name() string {
+	if a.As != "" {
+		return a.As
+	}
+	if a.Field == "" {
+		return aggNames[a.Op]
+	}
+	return aggNames[a.Op] + "_" + a.Field
+}
+
+type aggGroup struct {
+	row    *DO
+	states []*aggState
+}
+
+// GroupBy groups the rows of m by the values of keys, in the order groups
+// are first seen, and returns one ordered object per group holding the
+// key values followed by the aggregates.
+
+func (m *DA) GroupBy(keys []string, aggs ...*Aggregate) *DA {
+	groups := make([]*aggGroup, 0)
+	index := make(map[string]*aggGroup)
+
+	for idx := range m.Element {
+		row := m.Element[idx]
+
+		values := make([]interface{}, len(keys))
+		for k := range keys {
+			values[k] = fieldValue(row, keys[k])
+		}
+
+		gkey := canonicalKey(&DA{Element: values})
+		group, ok := index[gkey]
+		if !ok {
+			group = &aggGroup{
+				row:    NewOrderedObject(),
+				states: make([]*aggState, len(aggs)),
+			}
+			for k := range keys {
+				group.row.Put(keys[k], cloneElement(values[k]))
+			}
+			for a := range aggs {
+				group.states[a] = &aggState{allInt: true}
+			}
+
+			index[gkey] = group
+			groups = append(groups, group)
+		}
+
+		for a, agg := range aggs {
+			if agg.Op == AGG_COUNT && agg.Field == "" {
+				group.states[a].count++
+				continue
+			}
+			if v := fieldValue(row, agg.Field); v != nil {
+				group.states[a].add(agg.Op, v)
+			}
+		}
+	}
+
+	ret := NewArray()
+	for _, group := range groups {
+		for a, agg := range aggs {
+			group.row.Put(agg.name(), group.states[a].result(agg.Op))
+		}
+		ret.Element = append(ret.Element, group.row)
+	}
+
+	return ret
+}
+
+func (m *DJSON) SortBy(keys ...*SortKey) (*DJSON, error) {
+	if !m.IsArray() {
+		return nil, NotArrayError
+	}
+	ret, _ := elementToDJSON(m.Array.SortBy(keys...))
+	return ret, nil
+}
+
+func (m *DJSON) Distinct(field string) (*DJSON, error) {
+	if !m.IsArray() {
+		return nil, NotArrayError
+	}
+	ret, _ := elementToDJSON(m.Array.Distinct(field))
+	return ret, nil
+}
+
+func (m *DJSON) GroupBy(keys []string, aggs ...*Aggregate) (*DJSON, error) {
+	if !m.IsArray() {
+		return nil, NotArrayError
+	}
+	ret, _ := elementToDJSON(m.Array.GroupBy(keys, aggs...))
+	return ret, nil
+}
@@ -0,0 +1,98 @@
+package djson
+
+import (
+	"errors"
+	"testing"
+)
+
+const aggregateTestDoc = `[
+	{"dept": "eng", "team": "a", "name": "kim", "salary": 100, "age": 30},
+	{"dept": "eng", "team": "b", "name": "lee", "salary": 150.5, "age": null},
+	{"dept": "ops", "team": "a", "name": "park", "salary": 80, "age": 41},
+	{"dept": "eng", "team": "a", "name": "choi", "salary": 120, "age": 25},
+	{"dept": "ops", "team": "a", "name": "jung", "age": 35}
+]`
+
+func TestGroupBy(t *testing.T) {
+	aJson := NewDJSON().Parse(aggregateTestDoc)
+
+	ret, err := aJson.GroupBy([]string{"dept"},
+		&Aggregate{Op: AGG_COUNT},
+		&Aggregate{Op: AGG_SUM, Field: "salary"},
+		&Aggregate{Op: AGG_AVG, Field: "age", As: "avg_age"},
+		&Aggregate{Op: AGG_MIN, Field: "name"},
+		&Aggregate{Op: AGG_MAX, Field: "salary"},
+		&Aggregate{Op: AGG_COUNT, Field: "salary"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[{"dept":"eng","count":3,"sum_salary":370.5,"avg_age":27.5,"min_name":"choi","max_salary":150.5,"count_salary":3},` +
+		`{"dept":"ops","count":2,"sum_salary":80,"avg_age":38,"min_name":"jung","max_salary":80,"count_salary":1}]`
+	if ret.ToString() != expected {
+		t.Fatalf("expected %s, got %s", expected, ret.ToString())
+	}
+
+	ret, _ = aJson.GroupBy([]string{"dept", "team"}, &Aggregate{Op: AGG_COUNT, As: "n"})
+	if ret.ToString() != `[{"dept":"eng","team":"a","n":2},{"dept":"eng","team":"b","n":1},{"dept":"ops","team":"a","n":2}]` {
+		t.Fatal(ret.ToString())
+	}
+}
+
+func TestGroupByExactKeys(t *testing.T) {
+	aJson := NewDJSON().Parse(`[{"id":9007199254740993,"v":1},{"id":9007199254740992,"v":2},{"id":1,"v":4},{"id":1.0,"v":8},{"id":"1","v":16}]`)
+
+	ret, err := aJson.GroupBy([]string{"id"}, &Aggregate{Op: AGG_SUM, Field: "v"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ret.ToString() != `[{"id":9007199254740993,"sum_v":1},{"id":9007199254740992,"sum_v":2},{"id":1,"sum_v":12},{"id":"1","sum_v":16}]` {
+		t.Fatal(ret.ToString())
+	}
+
+	distinct, _ := aJson.Distinct("id")
+	if distinct.ToString() != `[9007199254740993,9007199254740992,1,"1"]` {
+		t.Fatal(distinct.ToString())
+	}
+}
+
+func TestDistinctAndSortBy(t *testing.T) {
+	aJson := NewDJSON().Parse(aggregateTestDoc)
+
+	ret, _ := aJson.Distinct("team")
+	if ret.ToString() != `["a","b"]` {
+		t.Fatal(ret.ToString())
+	}
+
+	ret, _ = aJson.Distinct("age")
+	if ret.ToString() != `[30,null,41,25,35]` {
+		t.Fatal(ret.ToString())
+	}
+
+	sorted, err := aJson.SortBy(&SortKey{Field: "dept", Desc: true}, &SortKey{Field: "age"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make([]string, 0)
+	for idx := 0; idx < sorted.Length(); idx++ {
+		names = append(names, sorted.GetAsStringPath(BuildPath(idx, "name")))
+	}
+	if len(names) != 5 || names[0] != "jung" || names[1] != "park" || names[2] != "choi" || names[3] != "kim" || names[4] != "lee" {
+		t.Fatal(names)
+	}
+
+	sorted, _ = aJson.SortBy(&SortKey{Field: "age", Desc: true, NullsFirst: true})
+	if sorted.GetAsStringPath(`[0]["name"]`) != "lee" || sorted.GetAsStringPath(`[1]["name"]`) != "park" {
+		t.Fatal(sorted.ToString())
+	}
+
+	if aJson.GetAsStringPath(`[0]["name"]`) != "kim" {
+		t.Fatal("source array was reordered")
+	}
+
+	if _, err := NewDJSON().Parse(`{"a":1}`).SortBy(); !errors.Is(err, NotArrayError) {
+		t.Fatal(err)
+	}
+}
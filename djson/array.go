@@ -12,14 +12,30 @@ import (
 type DA struct {
 	SeekPointer int
 	Element     []interface{}
+	lossless    bool
 }
 
 func NewArray() *DA {
 	return &DA{
-		Element: make([]interface{}, 0),
+		Element:  make([]interface{}, 0),
+		lossless: losslessNumbers.Load(),
 	}
 }
 
+func (m *DA) ParseWithError(doc string) (*DA, error) {
+	ret, err := parseDocument(doc, false, m.lossless)
+	if err != nil {
+		return m, err
+	}
+
+	if ret.JsonType != JSON_ARRAY {
+		return m, newParseErrorAt(doc, firstValueOffset(doc)+1, NotArrayError, "not array")
+	}
+
+	*m = *ret.Array
+	return m, nil
+}
+
 func (m *DA) PushBack(values interface{}) *DA {
 	return m.Insert(m.Size(), values)
 }
@@ -54,6 +70,12 @@ func (m *DA) ReplaceAt(idx int, value interface{}) *DA {
 	}
 
 	if n, ok := value.(json.Number); ok {
+		if m.lossless {
+			if isNumberLiteral(string(n)) {
+				m.Element[idx] = n
+			}
+			return m
+		}
 		if i, err := n.Int64(); err == nil {
 			m.Element[idx] = i
 			return m
@@ -303,7 +325,12 @@ func (m *DA) GetType(idx int) (string, bool) {
 		return "", false
 	}
 
-	switch m.Element[idx].(type) {
+	switch t := m.Element[idx].(type) {
+	case json.Number:
+		if isIntLiteral(t) {
+			return "int", true
+		}
+		return "float", true
 	case DA, *DA:
 		return "array", true
 	case DO, *DO:
@@ -436,13 +463,34 @@ func (m *DA) GetAsString2(idx int) (string, bool) {
 }
 
 func (m *DA) ToStringPretty() string {
-	jsonByte, _ := json.MarshalIndent(ConvertArrayToSlice(m), "", "   ")
-	return string(jsonByte)
+	return encodeElementIndent(m)
 }
 
 func (m *DA) ToString() string {
-	jsonByte, _ := json.Marshal(ConvertArrayToSlice(m))
-	return string(jsonByte)
+	return encodeElement(m)
+}
+
+// compareString compares rune by rune, as the sort functions order strings.
+
+func compareString(a, b string) int {
+	aRune := []rune(a)
+	bRune := []rune(b)
+
+	lenToInspect := len(aRune)
+	if len(bRune) < lenToInspect {
+		lenToInspect = len(bRune)
+	}
+
+	for k := 0; k < lenToInspect; k++ {
+		if aRune[k] < bRune[k] {
+			return -1
+		}
+		if aRune[k] > bRune[k] {
+			return 1
+		}
+	}
+
+	return len(aRune) - len(bRune)
 }
 
 func (m *DA) SortObject(isAsc bool, key string) bool {
@@ -484,28 +532,7 @@ func (m *DA) SortObject(isAsc bool, key string) bool {
 
 			switch elemType {
 			case "string":
-
-				iRune := []rune(ido.GetAsString(key))
-				jRune := []rune(jdo.GetAsString(key))
-
-				lenToInspect := len(iRune)
-				if len(jRune) < lenToInspect {
-					lenToInspect = len(jRune)
-				}
-
-				for k := 0; k < lenToInspect; k++ {
-					if iRune[k] == jRune[k] {
-						continue
-					}
-
-					if iRune[k] < jRune[k] {
-						return true
-					}
-
-					return false
-				}
-
-				return len(iRune) < len(jRune)
+				return compareString(ido.GetAsString(key), jdo.GetAsString(key)) < 0
 
 			case "int", "uint", "int8", "uint8", "int16", "uint16", "int32", "uint32", "int64", "uint64":
 				iInt, _ := ido.GetAsInt(key)
@@ -531,28 +558,7 @@ func (m *DA) SortObject(isAsc bool, key string) bool {
 
 			switch elemType {
 			case "string":
-
-				iRune := []rune(ido.GetAsString(key))
-				jRune := []rune(jdo.GetAsString(key))
-
-				lenToInspect := len(iRune)
-				if len(jRune) < lenToInspect {
-					lenToInspect = len(jRune)
-				}
-
-				for k := 0; k < lenToInspect; k++ {
-					if iRune[k] == jRune[k] {
-						continue
-					}
-
-					if iRune[k] > jRune[k] {
-						return true
-					}
-
-					return false
-				}
-
-				return len(iRune) > len(jRune)
+				return compareString(ido.GetAsString(key), jdo.GetAsString(key)) > 0
 
 			case "int", "uint", "int8", "uint8", "int16", "uint16", "int32", "uint32", "int64", "uint64":
 				iInt, _ := ido.GetAsInt(key)
@@ -684,6 +690,13 @@ func (m *DA) Equal(t *DA) bool {
 			return false
 		}
 
+		if eq, ok := numberEqual(m.Element[i], t.Element[i]); ok {
+			if !eq {
+				return false
+			}
+			continue
+		}
+
 		mtype := reflect.TypeOf(m.Element[i]).String()
 		ttype := reflect.TypeOf(t.Element[i]).String()
 
@@ -744,10 +757,12 @@ func (m *DA) Clone() *DA {
 	t := NewArray()
 
 	t.Element = make([]interface{}, m.Size())
+	t.lossless = m.lossless
 
 	for i := range m.Element {
 		if m.Element[i] == nil {
 			t.Element[i] = nil
+			continue
 		}
 
 		mtype := reflect.TypeOf(m.Element[i]).String()
@@ -761,6 +776,8 @@ func (m *DA) Clone() *DA {
 			t.Element[i], _ = m.GetAsInt(i)
 		case "float32", "float64":
 			t.Element[i], _ = m.GetAsFloat(i)
+		case "json.Number":
+			t.Element[i] = m.Element[i]
 		case "*djson.DO":
 			mdo := m.Element[i].(*DO)
 			t.Element[i] = mdo.Clone()
@@ -0,0 +1,133 @@
+package djson
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// Shared helpers for the CBOR and MessagePack codecs.
+
+const binaryMaxDepth = 1000
+
+const (
+	binaryNumInt = iota
+	binaryNumUint
+	binaryNumBig
+	binaryNumFloat
+)
+
+type binaryReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *binaryReader) errorf(code error, format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s at offset %d", code, fmt.Sprintf(format, args...), r.pos)
+}
+
+func (r *binaryReader) readByte() (byte, error) {
+	if r.pos >= len(r.data) {
+		return 0, r.errorf(UnexpectedEndError, "need 1 byte")
+	}
+	r.pos++
+	return r.data[r.pos-1], nil
+}
+
+func (r *binaryReader) read(n uint64) ([]byte, error) {
+	if n > uint64(len(r.data)-r.pos) {
+		return nil, r.errorf(UnexpectedEndError, "need %d bytes", n)
+	}
+	ret := r.data[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+	return ret, nil
+}
+
+func (r *binaryReader) readUint(size int) (uint64, error) {
+	b, err := r.read(uint64(size))
+	if err != nil {
+		return 0, err
+	}
+
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+	return u, nil
+}
+
+// capacity bounds a declared element count by the bytes left, since every
+// element takes at least one byte.
+
+func (r *binaryReader) capacity(n uint64) int {
+	if left := uint64(len(r.data) - r.pos); n > left {
+		return int(left)
+	}
+	return int(n)
+}
+
+func binaryNumber(element interface{}) (kind int, i int64, u uint64, b *big.Int, f float64) {
+	switch t := element.(type) {
+	case uint64:
+		if t > math.MaxInt64 {
+			return binaryNumUint, 0, t, nil, 0
+		}
+	case uint:
+		if uint64(t) > math.MaxInt64 {
+			return binaryNumUint, 0, uint64(t), nil, 0
+		}
+	case json.Number:
+		if !isIntLiteral(t) {
+			f, _ = getFloatBase(t)
+			return binaryNumFloat, 0, 0, nil, f
+		}
+		b, _ = getBigIntBase(t)
+		if b.IsInt64() {
+			return binaryNumInt, b.Int64(), 0, nil, 0
+		}
+		if b.IsUint64() {
+			return binaryNumUint, 0, b.Uint64(), nil, 0
+		}
+		return binaryNumBig, 0, 0, b, 0
+	}
+
+	if elementType(element) == JSON_FLOAT {
+		f, _ = getFloatBase(element)
+		return binaryNumFloat, 0, 0, nil, f
+	}
+
+	i, _ = getIntBase(element)
+	return binaryNumInt, i, 0, nil, 0
+}
+
+// binaryMapKey converts a decoded map key to an object key. Integer keys,
+// common in compact encodings, become their decimal text.
+
+func binaryMapKey(r *binaryReader, key interface{}) (string, error) {
+	switch t := key.(type) {
+	case string:
+		return t, nil
+	case int64, uint64, json.Number:
+		s, _ := getStringBase(t)
+		return s, nil
+	}
+
+	return "", r.errorf(InvalidBinaryError, "unsupported map key type %T", key)
+}
+
+func binaryResult(element interface{}, r *binaryReader) (*DJSON, error) {
+	if r.pos != len(r.data) {
+		return nil, r.errorf(TrailingDataError, "%d bytes left", len(r.data)-r.pos)
+	}
+
+	ret, _ := elementToDJSON(element)
+	return ret, nil
+}
+
+func binaryFloat(r *binaryReader, f float64) (interface{}, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, r.errorf(UnsupportedValueError, "%v has no JSON representation", f)
+	}
+	return f, nil
+}
@@ -0,0 +1,142 @@
+package djson
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+const binaryTestDoc = `{"id":7,"name":"sensor","ratio":0.1,"ok":true,"none":null,"neg":-70000,"values":[1,2.5,"x",[],{}],"big":18446744073709551616}`
+
+func TestCBOR(t *testing.T) {
+	aJson := NewDJSON().SetLosslessNumbers(true).Parse(binaryTestDoc)
+
+	data, err := aJson.ToCBOR()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := NewDJSON().ParseCBOR(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !decoded.Equal(aJson) || !decoded.IsFloat("ratio") || !decoded.IsInt("id") {
+		t.Fatal(decoded.ToString())
+	}
+
+	testCases := [][2]string{
+		{"00", `0`},
+		{"1864", `100`},
+		{"3903e7", `-1000`},
+		{"f93e00", `1.5`},
+		{"6161", `"a"`},
+		{"8301820203820405", `[1,[2,3],[4,5]]`},
+		{"a26161016162820203", `{"a":1,"b":[2,3]}`},
+		{"c249010000000000000000", `18446744073709551616`},
+		{"4401020304", `"AQIDBA=="`},
+		{"9f018202039f0405ffff", `[1,[2,3],[4,5]]`},
+		{"7f657374726561646d696e67ff", `"streaming"`},
+		{"c074323031332d30332d32315432303a30343a30305a", `"2013-03-21T20:04:00Z"`},
+	}
+
+	for _, tc := range testCases {
+		raw, _ := hex.DecodeString(tc[0])
+		ret, err := NewDJSON().ParseCBOR(raw)
+		if err != nil {
+			t.Fatalf("%s: %v", tc[0], err)
+		}
+
+		expected, _ := NewDJSON().SetLosslessNumbers(true).ParseWithError(tc[1])
+		if !ret.Equal(expected) {
+			t.Fatalf("%s: expected %s, got %s", tc[0], tc[1], ret.ToString())
+		}
+	}
+
+	if data, _ := NewDJSON().Parse(`{"a":1,"b":[2,3]}`).ToCBOR(); hex.EncodeToString(data) != "a26161016162820203" {
+		t.Fatal(hex.EncodeToString(data))
+	}
+
+	if _, err := NewDJSON().ParseCBOR([]byte{0x82, 0x01}); !errors.Is(err, UnexpectedEndError) {
+		t.Fatal(err)
+	}
+
+	if _, err := NewDJSON().ParseCBOR([]byte{0x01, 0x02}); !errors.Is(err, TrailingDataError) {
+		t.Fatal(err)
+	}
+
+	if _, err := NewObject().ParseCBOR([]byte{0x80}); !errors.Is(err, NotObjectError) {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{"c26441513d3d", "c201", "c3a0", "c2c24101"} {
+		raw, _ := hex.DecodeString(s)
+		if _, err := NewDJSON().ParseCBOR(raw); !errors.Is(err, InvalidBinaryError) {
+			t.Fatal(s, err)
+		}
+	}
+}
+
+func TestMsgPack(t *testing.T) {
+	aJson := NewDJSON().Parse(binaryTestDoc)
+
+	data, err := aJson.ToMsgPack()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := NewDJSON().ParseMsgPack(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !decoded.Equal(aJson) || !decoded.IsFloat("ratio") {
+		t.Fatal(decoded.ToString())
+	}
+
+	obj := NewOrderedObject().Put("compact", true).Put("schema", 0)
+	if data, _ := obj.ToMsgPack(); hex.EncodeToString(data) != "82a7636f6d70616374c3a6736368656d6100" {
+		t.Fatal(hex.EncodeToString(data))
+	}
+
+	testCases := [][2]string{
+		{"d1fc18", `-1000`},
+		{"cf8000000000000000", `9223372036854775808`},
+		{"c403010203", `"AQID"`},
+		{"cb3ff8000000000000", `1.5`},
+		{"dc000201a161", `[1,"a"]`},
+		{"d6ff00000000", `"1970-01-01T00:00:00Z"`},
+		{"810102", `{"1":2}`},
+	}
+
+	for _, tc := range testCases {
+		raw, _ := hex.DecodeString(tc[0])
+		ret, err := NewDJSON().ParseMsgPack(raw)
+		if err != nil {
+			t.Fatalf("%s: %v", tc[0], err)
+		}
+
+		expected, _ := NewDJSON().SetLosslessNumbers(true).ParseWithError(tc[1])
+		if !ret.Equal(expected) {
+			t.Fatalf("%s: expected %s, got %s", tc[0], tc[1], ret.ToString())
+		}
+	}
+
+	if _, err := NewDJSON().ParseMsgPack([]byte{0xc1}); !errors.Is(err, InvalidBinaryError) {
+		t.Fatal(err)
+	}
+
+	if _, err := NewDJSON().ParseMsgPack([]byte{0xdb, 0xff, 0xff, 0xff, 0xff}); !errors.Is(err, UnexpectedEndError) {
+		t.Fatal(err)
+	}
+
+	if data, _ := NewDJSON().SetLosslessNumbers(true).Parse(`[18446744073709551615]`).ToMsgPack(); hex.EncodeToString(data) != "91cfffffffffffffffff" {
+		t.Fatal(hex.EncodeToString(data))
+	}
+
+	for _, s := range []string{`[18446744073709551616]`, `{"a":-9223372036854775809}`} {
+		if _, err := NewDJSON().SetLosslessNumbers(true).Parse(s).ToMsgPack(); !errors.Is(err, UnsupportedValueError) {
+			t.Fatal(s, err)
+		}
+	}
+}
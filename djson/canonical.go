@@ -0,0 +1,230 @@
+package djson
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Canonical serialization follows the JSON Canonicalization Scheme
+// (RFC 8785): keys sorted by UTF-16 code units, ES6 number formatting
+// and minimal string escaping, so that hashes and signatures over a
+// document are reproducible.
+
+func (m *DJSON) ToCanonical() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, m.GetAsInterface()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (m *DJSON) ToCanonicalString() string {
+	b, err := m.ToCanonical()
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func (m *DO) ToCanonical() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (m *DO) ToCanonicalString() string {
+	b, err := m.ToCanonical()
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func (m *DA) ToCanonical() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (m *DA) ToCanonicalString() string {
+	b, err := m.ToCanonical()
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func lessUTF16(a, b string) bool {
+	ua := utf16.Encode([]rune(a))
+	ub := utf16.Encode([]rune(b))
+
+	for idx := 0; idx < len(ua) && idx < len(ub); idx++ {
+		if ua[idx] != ub[idx] {
+			return ua[idx] < ub[idx]
+		}
+	}
+
+	return len(ua) < len(ub)
+}
+
+func writeCanonical(buf *bytes.Buffer, element interface{}) error {
+	switch t := element.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(t))
+	case string:
+		writeCanonicalString(buf, t)
+	case *DO:
+		if t == nil {
+			buf.WriteString("null")
+			return nil
+		}
+
+		keys := make([]string, 0, len(t.Map))
+		for k := range t.Map {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return lessUTF16(keys[i], keys[j])
+		})
+
+		buf.WriteByte('{')
+		for idx, k := range keys {
+			if idx > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, k)
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, t.Map[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case *DA:
+		if t == nil {
+			buf.WriteString("null")
+			return nil
+		}
+
+		buf.WriteByte('[')
+		for idx := range t.Element {
+			if idx > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, t.Element[idx]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case json.Number:
+		f, err := t.Float64()
+		if err != nil {
+			return UnsupportedValueError
+		}
+		return writeCanonicalNumber(buf, f)
+	case uint, uint64, uintptr:
+		u, _ := getStringBase(t)
+		f, _ := strconv.ParseFloat(u, 64)
+		return writeCanonicalNumber(buf, f)
+	default:
+		switch elementType(element) {
+		case JSON_INT:
+			i, _ := getIntBase(element)
+			return writeCanonicalNumber(buf, float64(i))
+		case JSON_FLOAT:
+			f, _ := getFloatBase(element)
+			return writeCanonicalNumber(buf, f)
+		}
+		return UnsupportedValueError
+	}
+
+	return nil
+}
+
+// writeCanonicalNumber formats f like ES6 Number.prototype.toString.
+
+func writeCanonicalNumber(buf *bytes.Buffer, f float64) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return UnsupportedValueError
+	}
+
+	if f == 0 {
+		buf.WriteByte('0')
+		return nil
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs < 1e-6 || abs >= 1e21 {
+		format = 'e'
+	}
+
+	b := strconv.AppendFloat(nil, f, format, -1, 64)
+	if format == 'e' {
+		// strconv pads the exponent to two digits, ES6 does not
+		n := len(b)
+		if n >= 4 && b[n-4] == 'e' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+
+	buf.Write(b)
+	return nil
+}
+
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+
+	buf.WriteByte('"')
+	for idx := 0; idx < len(s); {
+		c := s[idx]
+
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[idx:])
+			if r == utf8.RuneError && size == 1 {
+				buf.WriteString(`�`)
+			} else {
+				buf.WriteString(s[idx : idx+size])
+			}
+			idx += size
+			continue
+		}
+
+		switch c {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[c>>4])
+				buf.WriteByte(hex[c&0xf])
+			} else {
+				buf.WriteByte(c)
+			}
+		}
+		idx++
+	}
+	buf.WriteByte('"')
+}
@@ -0,0 +1,37 @@
+package djson
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCanonical(t *testing.T) {
+	testCases := [][2]string{
+		{`{"b":2,"a":1,"c":{"z":[3,{"y":1,"x":2}],"a":null}}`, `{"a":1,"b":2,"c":{"a":null,"z":[3,{"x":2,"y":1}]}}`},
+		{`[1.0, -0.0, 1e21, 1e-7, 123456789012, 0.000001, 333333333.33333329, 1E30, 4.50]`, `[1,0,1e+21,1e-7,123456789012,0.000001,333333333.3333333,1e+30,4.5]`},
+		{`{"€":"€","\r":"\u000f","😀":"<>&","\u0080":"\"\\/"}`, `{"\r":"\u000f","` + "\u0080" + `":"\"\\/","€":"€","😀":"<>&"}`},
+		{`"plain"`, `"plain"`},
+		{`true`, `true`},
+	}
+
+	for _, tc := range testCases {
+		aJson, err := NewDJSON().ParseWithError(tc[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := aJson.ToCanonicalString(); got != tc[1] {
+			t.Fatalf("%s: expected %s, got %s", tc[0], tc[1], got)
+		}
+	}
+
+	obj := NewObject().Put("k", 1).Put("a", NewArray().Put([]interface{}{"x", 2.5}))
+	if obj.ToCanonicalString() != `{"a":["x",2.5],"k":1}` {
+		t.Fatal(obj.ToCanonicalString())
+	}
+
+	inf := &DA{Element: []interface{}{math.Inf(1)}}
+	if _, err := inf.ToCanonical(); err == nil {
+		t.Fatal("expected error for infinity")
+	}
+}
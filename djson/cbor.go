@@ -0,0 +1,436 @@
+package djson
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"math/big"
+	"strconv"
+	"unicode/utf8"
+)
+
+// CBOR (RFC 8949) encoding. Integers beyond 64 bits use the bignum tags,
+// whose content must be a byte string, byte strings decode to base64 text,
+// and other tags are dropped in favour of their content.
+
+const (
+	cborUint    = 0
+	cborNegInt  = 1
+	cborBytes   = 2
+	cborText    = 3
+	cborArray   = 4
+	cborMap     = 5
+	cborTag     = 6
+	cborSimple  = 7
+	cborIndef   = 31
+	cborBreak   = 0xff
+	cborTagBigP = 2
+	cborTagBigN = 3
+)
+
+func (m *DJSON) ToCBOR() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeCBOR(&buf, m.GetAsInterface()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (m *DO) ToCBOR() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeCBOR(&buf, m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (m *DA) ToCBOR() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeCBOR(&buf, m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ParseCBOR decodes a single CBOR data item. On error m is left unchanged.
+
+func (m *DJSON) ParseCBOR(data []byte) (*DJSON, error) {
+	r := &binaryReader{data: data}
+
+	element, err := readCBOR(r, 0)
+	if err != nil {
+		return m, err
+	}
+
+	ret, err := binaryResult(element, r)
+	if err != nil {
+		return m, err
+	}
+
+	*m = *ret
+	return m, nil
+}
+
+func (m *DO) ParseCBOR(data []byte) (*DO, error) {
+	ret, err := NewDJSON().ParseCBOR(data)
+	if err != nil {
+		return m, err
+	}
+
+	if !ret.IsObject() {
+		return m, NotObjectError
+	}
+
+	*m = *ret.Object
+	return m, nil
+}
+
+func (m *DA) ParseCBOR(data []byte) (*DA, error) {
+	ret, err := NewDJSON().ParseCBOR(data)
+	if err != nil {
+		return m, err
+	}
+
+	if !ret.IsArray() {
+		return m, NotArrayError
+	}
+
+	*m = *ret.Array
+	return m, nil
+}
+
+func writeCBORHead(buf *bytes.Buffer, major byte, n uint64) {
+	switch {
+	case n < 24:
+		buf.WriteByte(major<<5 | byte(n))
+	case n <= math.MaxUint8:
+		buf.WriteByte(major<<5 | 24)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(major<<5 | 25)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	case n <= math.MaxUint32:
+		buf.WriteByte(major<<5 | 26)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	default:
+		buf.WriteByte(major<<5 | 27)
+		buf.Write(binary.BigEndian.AppendUint64(nil, n))
+	}
+}
+
+func writeCBOR(buf *bytes.Buffer, element interface{}) error {
+	switch t := element.(type) {
+	case nil:
+		buf.WriteByte(cborSimple<<5 | 22)
+	case bool:
+		if t {
+			buf.WriteByte(cborSimple<<5 | 21)
+		} else {
+			buf.WriteByte(cborSimple<<5 | 20)
+		}
+	case string:
+		writeCBORHead(buf, cborText, uint64(len(t)))
+		buf.WriteString(t)
+	case DO:
+		return writeCBOR(buf, &t)
+	case DA:
+		return writeCBOR(buf, &t)
+	case *DJSON:
+		return writeCBOR(buf, t.GetAsInterface())
+	case *DO:
+		keys := t.Keys()
+		writeCBORHead(buf, cborMap, uint64(len(keys)))
+		for _, k := range keys {
+			writeCBORHead(buf, cborText, uint64(len(k)))
+			buf.WriteString(k)
+			if err := writeCBOR(buf, t.Map[k]); err != nil {
+				return err
+			}
+		}
+	case *DA:
+		writeCBORHead(buf, cborArray, uint64(len(t.Element)))
+		for idx := range t.Element {
+			if err := writeCBOR(buf, t.Element[idx]); err != nil {
+				return err
+			}
+		}
+	default:
+		et := elementType(element)
+		if et != JSON_INT && et != JSON_FLOAT {
+			return UnsupportedValueError
+		}
+
+		kind, i, u, b, f := binaryNumber(element)
+		switch kind {
+		case binaryNumInt:
+			if i >= 0 {
+				writeCBORHead(buf, cborUint, uint64(i))
+			} else {
+				writeCBORHead(buf, cborNegInt, uint64(-(i + 1)))
+			}
+		case binaryNumUint:
+			writeCBORHead(buf, cborUint, u)
+		case binaryNumBig:
+			tag := uint64(cborTagBigP)
+			if b.Sign() < 0 {
+				tag = cborTagBigN
+				b = new(big.Int).Sub(new(big.Int).Neg(b), big.NewInt(1))
+			}
+			writeCBORHead(buf, cborTag, tag)
+			writeCBORHead(buf, cborBytes, uint64(len(b.Bytes())))
+			buf.Write(b.Bytes())
+		case binaryNumFloat:
+			if f32 := float32(f); float64(f32) == f {
+				buf.WriteByte(cborSimple<<5 | 26)
+				buf.Write(binary.BigEndian.AppendUint32(nil, math.Float32bits(f32)))
+			} else {
+				buf.WriteByte(cborSimple<<5 | 27)
+				buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(f)))
+			}
+		}
+	}
+
+	return nil
+}
+
+func readCBORArgument(r *binaryReader, info byte) (uint64, error) {
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info == 24:
+		return r.readUint(1)
+	case info == 25:
+		return r.readUint(2)
+	case info == 26:
+		return r.readUint(4)
+	case info == 27:
+		return r.readUint(8)
+	}
+
+	return 0, r.errorf(InvalidBinaryError, "invalid additional information %d", info)
+}
+
+// readCBORString reads a byte or text string, joining indefinite-length
+// chunks.
+
+func readCBORString(r *binaryReader, major byte, info byte) ([]byte, error) {
+	if info != cborIndef {
+		n, err := readCBORArgument(r, info)
+		if err != nil {
+			return nil, err
+		}
+		return r.read(n)
+	}
+
+	var chunks []byte
+	for {
+		c, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		if c == cborBreak {
+			return chunks, nil
+		}
+		if c>>5 != major || c&0x1f == cborIndef {
+			return nil, r.errorf(InvalidBinaryError, "invalid chunk in indefinite-length string")
+		}
+
+		chunk, err := readCBORString(r, major, c&0x1f)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk...)
+	}
+}
+
+func halfToFloat(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+
+	if h&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
+
+func readCBOR(r *binaryReader, depth int) (interface{}, error) {
+	if depth > binaryMaxDepth {
+		return nil, r.errorf(InvalidBinaryError, "nesting too deep")
+	}
+
+	c, err := r.readByte()
+	if err != nil {
+		return nil, err
+	}
+
+	major, info := c>>5, c&0x1f
+
+	switch major {
+	case cborUint, cborNegInt:
+		n, err := readCBORArgument(r, info)
+		if err != nil {
+			return nil, err
+		}
+		if major == cborUint {
+			if n > math.MaxInt64 {
+				return json.Number(strconv.FormatUint(n, 10)), nil
+			}
+			return int64(n), nil
+		}
+		if n > math.MaxInt64 {
+			b := new(big.Int).SetUint64(n)
+			return json.Number(b.Neg(b).Sub(b, big.NewInt(1)).String()), nil
+		}
+		return -int64(n) - 1, nil
+	case cborBytes:
+		b, err := readCBORString(r, major, info)
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString(b), nil
+	case cborText:
+		b, err := readCBORString(r, major, info)
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.Valid(b) {
+			return nil, r.errorf(InvalidBinaryError, "invalid UTF-8 in text string")
+		}
+		return string(b), nil
+	case cborArray:
+		arr := NewArray()
+		if info == cborIndef {
+			for {
+				if r.pos < len(r.data) && r.data[r.pos] == cborBreak {
+					r.pos++
+					return arr, nil
+				}
+				v, err := readCBOR(r, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				arr.Element = append(arr.Element, v)
+			}
+		}
+
+		n, err := readCBORArgument(r, info)
+		if err != nil {
+			return nil, err
+		}
+		arr.Element = make([]interface{}, 0, r.capacity(n))
+		for idx := uint64(0); idx < n; idx++ {
+			v, err := readCBOR(r, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			arr.Element = append(arr.Element, v)
+		}
+		return arr, nil
+	case cborMap:
+		obj := NewObject()
+
+		n := uint64(math.MaxUint64)
+		if info != cborIndef {
+			if n, err = readCBORArgument(r, info); err != nil {
+				return nil, err
+			}
+		}
+
+		for idx := uint64(0); idx < n; idx++ {
+			if info == cborIndef && r.pos < len(r.data) && r.data[r.pos] == cborBreak {
+				r.pos++
+				break
+			}
+
+			k, err := readCBOR(r, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			key, err := binaryMapKey(r, k)
+			if err != nil {
+				return nil, err
+			}
+
+			v, err := readCBOR(r, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			obj.set(key, v)
+		}
+		return obj, nil
+	case cborTag:
+		tag, err := readCBORArgument(r, info)
+		if err != nil {
+			return nil, err
+		}
+
+		bignum := tag == cborTagBigP || tag == cborTagBigN
+		if bignum && r.pos < len(r.data) && r.data[r.pos]>>5 != cborBytes {
+			return nil, r.errorf(InvalidBinaryError, "bignum content is not a byte string")
+		}
+
+		v, err := readCBOR(r, depth+1)
+		if err != nil {
+			return nil, err
+		}
+
+		if bignum {
+			raw, _ := base64.StdEncoding.DecodeString(v.(string))
+			b := new(big.Int).SetBytes(raw)
+			if tag == cborTagBigN {
+				b.Neg(b).Sub(b, big.NewInt(1))
+			}
+			if b.IsInt64() {
+				return b.Int64(), nil
+			}
+			return json.Number(b.String()), nil
+		}
+
+		return v, nil
+	}
+
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		h, err := r.readUint(2)
+		if err != nil {
+			return nil, err
+		}
+		return binaryFloat(r, halfToFloat(uint16(h)))
+	case 26:
+		u, err := r.readUint(4)
+		if err != nil {
+			return nil, err
+		}
+		return binaryFloat(r, float64(math.Float32frombits(uint32(u))))
+	case 27:
+		u, err := r.readUint(8)
+		if err != nil {
+			return nil, err
+		}
+		return binaryFloat(r, math.Float64frombits(u))
+	}
+
+	return nil, r.errorf(InvalidBinaryError, "unsupported simple value %d", info)
+}
@@ -0,0 +1,202 @@
+package djson
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lokks307/go-util/mt"
+)
+
+var (
+	csvIntRegExp   = regexp.MustCompile(`^-?(0|[1-9][0-9]*)$`)
+	csvFloatRegExp = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+([eE][+-]?[0-9]+)?|[eE][+-]?[0-9]+)$`)
+)
+
+// CSVOptions controls ParseCSV and ToCSV. Comma defaults to ','; use '\t'
+// for TSV. With InferTypes, cells that look like int, float or bool are
+// read as such; numbers with leading zeros, like phone numbers, stay
+// strings. Columns sets the column order on write; by default columns
+// follow the key order of the rows as first seen. CP949 decodes the input
+// or encodes the output with the table in mt.
+
+type CSVOptions struct {
+	Comma      rune
+	LazyQuotes bool
+	InferTypes bool
+	Columns    []string
+	CP949      bool
+}
+
+func newCSVOptions(opts []*CSVOptions) *CSVOptions {
+	o := &CSVOptions{}
+	if len(opts) > 0 && opts[0] != nil {
+		*o = *opts[0]
+	}
+	if o.Comma == 0 {
+		o.Comma = ','
+	}
+	return o
+}
+
+// ParseCSV reads a header row and the rows below it into an array of
+// ordered objects keyed by the header. On error m is left unchanged and
+// the error is a *ParseError carrying the line.
+
+func (m *DJSON) ParseCSV(data []byte, opts ...*CSVOptions) (*DJSON, error) {
+	o := newCSVOptions(opts)
+
+	if o.CP949 {
+		data = mt.FromCP949(data)
+	}
+	doc := strings.TrimPrefix(string(data), "\ufeff")
+
+	r := csv.NewReader(strings.NewReader(doc))
+	r.Comma = o.Comma
+	r.LazyQuotes = o.LazyQuotes
+
+	records, err := r.ReadAll()
+	if err != nil {
+		var perr *csv.ParseError
+		if errors.As(err, &perr) {
+			offset := lineOffset(doc, perr.Line, 1)
+			if perr.Column > 1 {
+				offset += int64(perr.Column - 1)
+			}
+			return m, newParseErrorAt(doc, offset, InvalidSyntaxError, perr.Err.Error())
+		}
+		return m, err
+	}
+
+	arr := NewArray()
+
+	if len(records) > 0 {
+		header := records[0]
+
+		seen := make(map[string]bool, len(header))
+		for _, name := range header {
+			if seen[name] {
+				return m, newParseErrorAt(doc, 0, InvalidSyntaxError, fmt.Sprintf("duplicate column %q", name))
+			}
+			seen[name] = true
+		}
+
+		for _, record := range records[1:] {
+			row := NewOrderedObject()
+			for idx, name := range header {
+				if o.InferTypes {
+					row.Put(name, inferCSVCell(record[idx]))
+				} else {
+					row.Put(name, record[idx])
+				}
+			}
+			arr.Element = append(arr.Element, row)
+		}
+	}
+
+	ret, _ := elementToDJSON(arr)
+	*m = *ret
+	return m, nil
+}
+
+func inferCSVCell(cell string) interface{} {
+	switch {
+	case csvIntRegExp.MatchString(cell):
+		if i, err := strconv.ParseInt(cell, 10, 64); err == nil {
+			return i
+		}
+	case csvFloatRegExp.MatchString(cell):
+		if f, err := strconv.ParseFloat(cell, 64); err == nil {
+			return f
+		}
+	case strings.EqualFold(cell, "true"):
+		return true
+	case strings.EqualFold(cell, "false"):
+		return false
+	}
+
+	return cell
+}
+
+// ToCSV writes an array of objects with a header row. null is written as
+// an empty cell and nested objects or arrays as JSON text.
+
+func (m *DJSON) ToCSV(opts ...*CSVOptions) ([]byte, error) {
+	if !m.IsArray() {
+		return nil, NotArrayError
+	}
+
+	o := newCSVOptions(opts)
+
+	rows := make([]*DO, 0, m.Array.Size())
+	for idx := range m.Array.Element {
+		row, ok := m.Array.Element[idx].(*DO)
+		if !ok {
+			return nil, fmt.Errorf("row %d: %w", idx, NotObjectError)
+		}
+		rows = append(rows, row)
+	}
+
+	columns := o.Columns
+	if len(columns) == 0 {
+		seen := make(map[string]bool)
+		for _, row := range rows {
+			for _, k := range row.Keys() {
+				if !seen[k] {
+					seen[k] = true
+					columns = append(columns, k)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = o.Comma
+
+	if err := w.Write(columns); err != nil {
+		return nil, err
+	}
+
+	record := make([]string, len(columns))
+	for _, row := range rows {
+		for idx, name := range columns {
+			record[idx] = csvCell(row.Map[name])
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+
+	if o.CP949 {
+		return mt.ToCP949(buf.Bytes()), nil
+	}
+	return buf.Bytes(), nil
+}
+
+func csvCell(element interface{}) string {
+	switch t := element.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float32:
+		return strconv.FormatFloat(float64(t), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case *DO, *DA:
+		return encodeElement(t)
+	}
+
+	s, _ := getStringBase(element)
+	return s
+}
@@ -0,0 +1,77 @@
+package djson
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lokks307/go-util/mt"
+)
+
+func TestParseCSV(t *testing.T) {
+	doc := "name,phone,age,score,active,memo\n" +
+		"김철수,01012345678,42,3.5,TRUE,\"a, b\"\n" +
+		"이영희,0212345678,7,1e3,false,\n"
+
+	aJson, err := NewDJSON().ParseCSV([]byte(doc), &CSVOptions{InferTypes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[{"name":"김철수","phone":"01012345678","age":42,"score":3.5,"active":true,"memo":"a, b"},` +
+		`{"name":"이영희","phone":"0212345678","age":7,"score":1000,"active":false,"memo":""}]`
+	if aJson.ToString() != expected {
+		t.Fatalf("expected %s, got %s", expected, aJson.ToString())
+	}
+
+	bJson, _ := NewDJSON().ParseCSV([]byte(doc))
+	if bJson.GetAsStringPath(`[0]["age"]`) != "42" || bJson.GetTypePath(`[0]["age"]`) != "string" {
+		t.Fatal(bJson.ToString())
+	}
+
+	out, err := aJson.ToCSV(&CSVOptions{Columns: []string{"age", "name", "memo"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "age,name,memo\n42,김철수,\"a, b\"\n7,이영희,\n" {
+		t.Fatal(string(out))
+	}
+}
+
+func TestCSVCP949(t *testing.T) {
+	aJson := NewDJSON().Parse(`[{"이름":"홍길동","값":null,"tags":["a"]},{"이름":"아무개","값":1.25}]`)
+
+	out, err := aJson.ToCSV(&CSVOptions{Comma: '\t', CP949: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out) != string(mt.ToCP949([]byte("tags\t값\t이름\n\"[\"\"a\"\"]\"\t\t홍길동\n\t1.25\t아무개\n"))) {
+		t.Fatal(mt.EUCKRtoUTF8(out))
+	}
+
+	bJson, err := NewDJSON().ParseCSV(out, &CSVOptions{Comma: '\t', CP949: true, InferTypes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bJson.GetAsStringPath(`[1]["이름"]`) != "아무개" || bJson.GetAsFloatPath(`[1]["값"]`) != 1.25 {
+		t.Fatal(bJson.ToString())
+	}
+}
+
+func TestParseCSVError(t *testing.T) {
+	_, err := NewDJSON().ParseCSV([]byte("a,b\n1,2\n3\n"))
+
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Line != 3 {
+		t.Fatal(err)
+	}
+
+	if _, err := NewDJSON().ParseCSV([]byte("a,a\n1,2\n")); !errors.As(err, &perr) {
+		t.Fatal(err)
+	}
+
+	if _, err := NewDJSON().Parse(`[1]`).ToCSV(); !errors.Is(err, NotObjectError) {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,610 @@
+package djson
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// decoderMaxDepth bounds the nesting of objects and arrays, so that a deeply
+// nested stream fails with a ParseError instead of overflowing the stack.
+
+const decoderMaxDepth = 1000
+
+const (
+	decoderStateInit = iota
+	decoderStateFirst
+	decoderStateNext
+	decoderStateDone
+)
+
+// Decoder reads JSON from a stream. Next yields the elements of a top-level
+// array one at a time so that the whole document never has to be in memory.
+
+type Decoder struct {
+	r        *bufio.Reader
+	offset   int64
+	line     int
+	column   int
+	prevLine int
+	recent   []byte
+	state    int
+	ordered  bool
+	lossless bool
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		r:        bufio.NewReader(r),
+		line:     1,
+		recent:   make([]byte, 0, 2*parseErrorSnippetLen),
+		state:    decoderStateInit,
+		lossless: losslessNumbers.Load(),
+	}
+}
+
+// SetOrdered makes decoded objects keep the key order of the input.
+
+func (d *Decoder) SetOrdered(on bool) *Decoder {
+	d.ordered = on
+	return d
+}
+
+// SetLosslessNumbers makes decoded numbers keep their literal as json.Number.
+
+func (d *Decoder) SetLosslessNumbers(on bool) *Decoder {
+	d.lossless = on
+	return d
+}
+
+// InputOffset returns the number of bytes consumed so far.
+
+func (d *Decoder) InputOffset() int64 {
+	return d.offset
+}
+
+// Decode reads one complete JSON value. It can be called repeatedly
+// on a stream of concatenated values; io.EOF is returned at the end.
+
+func (d *Decoder) Decode() (*DJSON, error) {
+	if d.state != decoderStateInit {
+		return nil, d.parseError(InvalidSyntaxError, "Decode called while reading array elements")
+	}
+
+	c, err := d.skipSpace()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := d.readValue(c, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, _ := elementToDJSON(v)
+	return ret, nil
+}
+
+// Next returns the next element of the top-level array.
+// io.EOF is returned after the closing bracket has been read.
+
+func (d *Decoder) Next() (*DJSON, error) {
+	switch d.state {
+	case decoderStateDone:
+		return nil, io.EOF
+	case decoderStateInit:
+		c, err := d.skipSpace()
+		if err != nil {
+			return nil, d.eofError(err)
+		}
+		if c != '[' {
+			return nil, d.syntaxError("expected '[' at start of array")
+		}
+		d.state = decoderStateFirst
+	}
+
+	c, err := d.skipSpace()
+	if err != nil {
+		return nil, d.eofError(err)
+	}
+
+	if d.state == decoderStateFirst {
+		if c == ']' {
+			return nil, d.finish()
+		}
+	} else {
+		if c == ']' {
+			return nil, d.finish()
+		}
+		if c != ',' {
+			return nil, d.syntaxError(fmt.Sprintf("invalid character %q after array element", c))
+		}
+		c, err = d.skipSpace()
+		if err != nil {
+			return nil, d.eofError(err)
+		}
+	}
+
+	v, err := d.readValue(c, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	d.state = decoderStateNext
+
+	ret, _ := elementToDJSON(v)
+	return ret, nil
+}
+
+func (d *Decoder) finish() error {
+	d.state = decoderStateDone
+
+	c, err := d.skipSpace()
+	if err == io.EOF {
+		return io.EOF
+	}
+	if err != nil {
+		return err
+	}
+
+	return d.parseError(TrailingDataError, fmt.Sprintf("invalid character %q after top-level value", c))
+}
+
+func (d *Decoder) readByte() (byte, error) {
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	d.offset++
+
+	if c == '\n' {
+		d.prevLine = d.column
+		d.line++
+		d.column = 0
+	} else if c&0xC0 != 0x80 {
+		d.column++
+	}
+
+	if len(d.recent) == cap(d.recent) {
+		d.recent = append(d.recent[:0], d.recent[parseErrorSnippetLen:]...)
+	}
+	d.recent = append(d.recent, c)
+
+	return c, nil
+}
+
+func (d *Decoder) unreadByte() {
+	if d.r.UnreadByte() != nil {
+		return
+	}
+
+	d.offset--
+
+	c := d.recent[len(d.recent)-1]
+	d.recent = d.recent[:len(d.recent)-1]
+
+	if c == '\n' {
+		d.line--
+		d.column = d.prevLine
+	} else if c&0xC0 != 0x80 {
+		d.column--
+	}
+}
+
+func (d *Decoder) skipSpace() (byte, error) {
+	for {
+		c, err := d.readByte()
+		if err != nil {
+			return 0, err
+		}
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			return c, nil
+		}
+	}
+}
+
+func (d *Decoder) parseError(code error, msg string) error {
+	head := d.recent
+	if len(head) > parseErrorSnippetLen {
+		head = head[len(head)-parseErrorSnippetLen:]
+	}
+
+	tail, _ := d.r.Peek(parseErrorSnippetLen)
+
+	column := d.column
+	if column == 0 {
+		column = 1
+	}
+
+	return &ParseError{
+		Code:    code,
+		Msg:     msg,
+		Offset:  d.offset,
+		Line:    d.line,
+		Column:  column,
+		Snippet: strings.ToValidUTF8(string(head)+string(tail), ""),
+	}
+}
+
+func (d *Decoder) syntaxError(msg string) error {
+	return d.parseError(InvalidSyntaxError, msg)
+}
+
+func (d *Decoder) eofError(err error) error {
+	if err == io.EOF {
+		return d.parseError(UnexpectedEndError, "unexpected end of JSON input")
+	}
+	return err
+}
+
+func (d *Decoder) readValue(c byte, depth int) (interface{}, error) {
+	if (c == '{' || c == '[') && depth >= decoderMaxDepth {
+		return nil, d.syntaxError("nesting too deep")
+	}
+
+	switch {
+	case c == '{':
+		return d.readObject(depth + 1)
+	case c == '[':
+		return d.readArray(depth + 1)
+	case c == '"':
+		return d.readString()
+	case c == 't':
+		return true, d.readLiteral("rue")
+	case c == 'f':
+		return false, d.readLiteral("alse")
+	case c == 'n':
+		return nil, d.readLiteral("ull")
+	case c == '-' || (c >= '0' && c <= '9'):
+		return d.readNumber(c)
+	}
+
+	return nil, d.syntaxError(fmt.Sprintf("invalid character %q looking for beginning of value", c))
+}
+
+func (d *Decoder) readObject(depth int) (interface{}, error) {
+	obj := NewObject()
+	obj.lossless = d.lossless
+	if d.ordered {
+		obj.SetOrdered(true)
+	}
+
+	c, err := d.skipSpace()
+	if err != nil {
+		return nil, d.eofError(err)
+	}
+
+	if c == '}' {
+		return obj, nil
+	}
+
+	for {
+		if c != '"' {
+			return nil, d.syntaxError(fmt.Sprintf("invalid character %q looking for beginning of object key string", c))
+		}
+
+		key, err := d.readString()
+		if err != nil {
+			return nil, err
+		}
+
+		c, err = d.skipSpace()
+		if err != nil {
+			return nil, d.eofError(err)
+		}
+		if c != ':' {
+			return nil, d.syntaxError(fmt.Sprintf("invalid character %q after object key", c))
+		}
+
+		c, err = d.skipSpace()
+		if err != nil {
+			return nil, d.eofError(err)
+		}
+
+		v, err := d.readValue(c, depth)
+		if err != nil {
+			return nil, err
+		}
+
+		obj.Put(key, v)
+
+		c, err = d.skipSpace()
+		if err != nil {
+			return nil, d.eofError(err)
+		}
+
+		if c == '}' {
+			return obj, nil
+		}
+		if c != ',' {
+			return nil, d.syntaxError(fmt.Sprintf("invalid character %q after object key:value pair", c))
+		}
+
+		c, err = d.skipSpace()
+		if err != nil {
+			return nil, d.eofError(err)
+		}
+	}
+}
+
+func (d *Decoder) readArray(depth int) (interface{}, error) {
+	arr := NewArray()
+	arr.lossless = d.lossless
+
+	c, err := d.skipSpace()
+	if err != nil {
+		return nil, d.eofError(err)
+	}
+
+	if c == ']' {
+		return arr, nil
+	}
+
+	for {
+		v, err := d.readValue(c, depth)
+		if err != nil {
+			return nil, err
+		}
+
+		arr.PutAsArray(v)
+
+		c, err = d.skipSpace()
+		if err != nil {
+			return nil, d.eofError(err)
+		}
+
+		if c == ']' {
+			return arr, nil
+		}
+		if c != ',' {
+			return nil, d.syntaxError(fmt.Sprintf("invalid character %q after array element", c))
+		}
+
+		c, err = d.skipSpace()
+		if err != nil {
+			return nil, d.eofError(err)
+		}
+	}
+}
+
+func (d *Decoder) readLiteral(rest string) error {
+	for idx := 0; idx < len(rest); idx++ {
+		c, err := d.readByte()
+		if err != nil {
+			return d.eofError(err)
+		}
+		if c != rest[idx] {
+			return d.syntaxError(fmt.Sprintf("invalid character %q in literal", c))
+		}
+	}
+
+	return nil
+}
+
+func (d *Decoder) readHex4() (rune, error) {
+	var r rune
+	for idx := 0; idx < 4; idx++ {
+		c, err := d.readByte()
+		if err != nil {
+			return 0, d.eofError(err)
+		}
+
+		switch {
+		case c >= '0' && c <= '9':
+			c = c - '0'
+		case c >= 'a' && c <= 'f':
+			c = c - 'a' + 10
+		case c >= 'A' && c <= 'F':
+			c = c - 'A' + 10
+		default:
+			return 0, d.syntaxError(fmt.Sprintf("invalid character %q in \\u hexadecimal character escape", c))
+		}
+
+		r = r*16 + rune(c)
+	}
+
+	return r, nil
+}
+
+func (d *Decoder) readString() (string, error) {
+	buf := make([]byte, 0, 16)
+
+	for {
+		c, err := d.readByte()
+		if err != nil {
+			return "", d.eofError(err)
+		}
+
+		switch {
+		case c == '"':
+			return string(buf), nil
+		case c < 0x20:
+			return "", d.syntaxError(fmt.Sprintf("invalid character %q in string literal", c))
+		case c != '\\':
+			buf = append(buf, c)
+			continue
+		}
+
+		c, err = d.readByte()
+		if err != nil {
+			return "", d.eofError(err)
+		}
+
+		switch c {
+		case '"', '\\', '/':
+			buf = append(buf, c)
+		case 'b':
+			buf = append(buf, '\b')
+		case 'f':
+			buf = append(buf, '\f')
+		case 'n':
+			buf = append(buf, '\n')
+		case 'r':
+			buf = append(buf, '\r')
+		case 't':
+			buf = append(buf, '\t')
+		case 'u':
+			r, err := d.readHex4()
+			if err != nil {
+				return "", err
+			}
+
+			if utf16.IsSurrogate(r) {
+				r2 := utf8.RuneError
+				if next, _ := d.r.Peek(2); len(next) == 2 && next[0] == '\\' && next[1] == 'u' {
+					_, _ = d.readByte()
+					_, _ = d.readByte()
+					low, err := d.readHex4()
+					if err != nil {
+						return "", err
+					}
+					r2 = utf16.DecodeRune(r, low)
+					if r2 == utf8.RuneError {
+						buf = utf8.AppendRune(buf, utf8.RuneError)
+						r2 = low
+					}
+				}
+				r = r2
+			}
+
+			buf = utf8.AppendRune(buf, r)
+		default:
+			return "", d.syntaxError(fmt.Sprintf("invalid character %q in string escape code", c))
+		}
+	}
+}
+
+func (d *Decoder) readNumber(c byte) (interface{}, error) {
+	buf := []byte{c}
+	isFloat := false
+
+	readDigits := func() (int, error) {
+		n := 0
+		for {
+			c, err := d.readByte()
+			if err == io.EOF {
+				return n, nil
+			}
+			if err != nil {
+				return n, err
+			}
+			if c < '0' || c > '9' {
+				d.unreadByte()
+				return n, nil
+			}
+			buf = append(buf, c)
+			n++
+		}
+	}
+
+	if c == '-' {
+		c, err := d.readByte()
+		if err != nil {
+			return nil, d.eofError(err)
+		}
+		if c < '0' || c > '9' {
+			return nil, d.syntaxError(fmt.Sprintf("invalid character %q in numeric literal", c))
+		}
+		buf = append(buf, c)
+	}
+
+	if buf[len(buf)-1] != '0' {
+		if _, err := readDigits(); err != nil {
+			return nil, err
+		}
+	}
+
+	if c, err := d.readByte(); err == nil {
+		if c == '.' {
+			isFloat = true
+			buf = append(buf, c)
+			if n, err := readDigits(); err != nil {
+				return nil, err
+			} else if n == 0 {
+				return nil, d.syntaxError("missing digits after decimal point in numeric literal")
+			}
+		} else {
+			d.unreadByte()
+		}
+	} else if err != io.EOF {
+		return nil, err
+	}
+
+	if c, err := d.readByte(); err == nil {
+		if c == 'e' || c == 'E' {
+			isFloat = true
+			buf = append(buf, c)
+
+			c, err := d.readByte()
+			if err != nil {
+				return nil, d.eofError(err)
+			}
+			if c == '+' || c == '-' {
+				buf = append(buf, c)
+			} else {
+				d.unreadByte()
+			}
+
+			if n, err := readDigits(); err != nil {
+				return nil, err
+			} else if n == 0 {
+				return nil, d.syntaxError("missing digits in exponent of numeric literal")
+			}
+		} else {
+			d.unreadByte()
+		}
+	} else if err != io.EOF {
+		return nil, err
+	}
+
+	if d.lossless {
+		return json.Number(buf), nil
+	}
+
+	if !isFloat {
+		if i, err := strconv.ParseInt(string(buf), 10, 64); err == nil {
+			return i, nil
+		}
+	}
+
+	f, err := strconv.ParseFloat(string(buf), 64)
+	if err != nil {
+		return nil, d.parseError(NumberRangeError, fmt.Sprintf("number %s out of range", string(buf)))
+	}
+
+	return f, nil
+}
+
+func parseDocument(doc string, ordered bool, lossless bool) (*DJSON, error) {
+	dec := NewDecoder(strings.NewReader(doc)).SetOrdered(ordered)
+	if lossless {
+		dec.SetLosslessNumbers(true)
+	}
+
+	ret, err := dec.Decode()
+	if err == io.EOF {
+		return nil, dec.eofError(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dec.finish(); err != io.EOF {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func firstValueOffset(doc string) int64 {
+	return int64(len(doc) - len(strings.TrimLeft(doc, " \t\r\n")))
+}
@@ -0,0 +1,130 @@
+package djson
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDecoderNext(t *testing.T) {
+	jsonDoc := `[
+		{"name": "Ricardo Longa", "idade": 28, "skills": ["Golang", "Android"]},
+		{"name": "Hery Victor", "idade": 32.5, "skills": []},
+		"😀 é",
+		null, true, -0, 12345678901234567890
+	]`
+
+	dec := NewDecoder(strings.NewReader(jsonDoc))
+
+	elems := make([]*DJSON, 0)
+	for {
+		each, err := dec.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		elems = append(elems, each)
+	}
+
+	if len(elems) != 7 {
+		t.Fatalf("expected 7 elements, got %d", len(elems))
+	}
+
+	if elems[0].GetAsString("name") != "Ricardo Longa" || elems[0].GetAsIntPath(`["idade"]`) != 28 {
+		t.Fatal(elems[0].ToString())
+	}
+
+	if !elems[1].IsFloat("idade") || !elems[1].IsArray("skills") {
+		t.Fatal(elems[1].ToString())
+	}
+
+	if elems[2].GetAsString() != "\U0001F600 é" {
+		t.Fatal(elems[2].GetAsString())
+	}
+
+	if !elems[3].IsNull() || !elems[4].GetAsBool() || !elems[5].IsInt() || !elems[6].IsFloat() {
+		t.Fatal("unexpected scalar types")
+	}
+
+	if _, err := dec.Next(); err != io.EOF {
+		t.Fatal("expected io.EOF after end of array")
+	}
+}
+
+func TestDecoderDecode(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(`{"a": 1} [1, 2] "text" 3.5`))
+
+	types := []int{JSON_OBJECT, JSON_ARRAY, JSON_STRING, JSON_FLOAT}
+	for _, jt := range types {
+		each, err := dec.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if each.JsonType != jt {
+			t.Fatalf("expected type %d, got %d", jt, each.JsonType)
+		}
+	}
+
+	if _, err := dec.Decode(); err != io.EOF {
+		t.Fatal("expected io.EOF")
+	}
+}
+
+func TestDecoderSyntaxError(t *testing.T) {
+	testCases := []struct {
+		doc    string
+		offset int64
+	}{
+		{`[1, 2,, 3]`, 7},
+		{`[{"a": tru}]`, 11},
+		{`[1, 2`, 5},
+		{`{"a": 1}`, 1},
+		{`[01]`, 3},
+		{`[1] x`, 5},
+	}
+
+	for _, tc := range testCases {
+		dec := NewDecoder(strings.NewReader(tc.doc))
+
+		var err error
+		for err == nil {
+			_, err = dec.Next()
+		}
+
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Fatalf("%s: expected parse error, got %v", tc.doc, err)
+		}
+
+		if perr.Offset != tc.offset {
+			t.Fatalf("%s: expected offset %d, got %d (%s)", tc.doc, tc.offset, perr.Offset, perr.Msg)
+		}
+	}
+}
+
+func TestDecoderDepth(t *testing.T) {
+	nested := strings.Repeat("[", decoderMaxDepth) + strings.Repeat("]", decoderMaxDepth)
+	if _, err := NewDecoder(strings.NewReader(nested)).Decode(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, doc := range []string{
+		strings.Repeat("[", decoderMaxDepth+1) + strings.Repeat("]", decoderMaxDepth+1),
+		strings.Repeat(`{"a":`, 1000000),
+	} {
+		_, err := NewDecoder(strings.NewReader(doc)).Decode()
+
+		var perr *ParseError
+		if !errors.As(err, &perr) || perr.Code != InvalidSyntaxError || perr.Msg != "nesting too deep" {
+			t.Fatalf("expected parse error at the limit, got %v", err)
+		}
+	}
+
+	dec := NewDecoder(strings.NewReader("[" + nested + "]"))
+	if _, err := dec.Next(); !errors.Is(err, InvalidSyntaxError) {
+		t.Fatal(err)
+	}
+}
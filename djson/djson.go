@@ -1,6 +1,7 @@
 package djson
 
 import (
+	"encoding/json"
 	"reflect"
 	"strconv"
 	"strings"
@@ -26,6 +27,9 @@ type DJSON struct {
 	Float    float64
 	Bool     bool
 	JsonType int
+	ordered  bool
+	lossless bool
+	number   json.Number
 }
 
 func NewDJSON(v ...int) *DJSON {
@@ -121,7 +125,7 @@ func NewArrayJSON(v ...interface{}) *DJSON {
 }
 
 func (m *DJSON) SetAsObject() *DJSON {
-	m.Object = NewObject()
+	m.Object = m.newObject()
 	m.Array = nil
 	m.JsonType = JSON_OBJECT
 
@@ -129,7 +133,7 @@ func (m *DJSON) SetAsObject() *DJSON {
 }
 
 func (m *DJSON) SetAsArray() *DJSON {
-	m.Array = NewArray()
+	m.Array = m.newArray()
 	m.Object = nil
 	m.JsonType = JSON_ARRAY
 
@@ -149,6 +153,21 @@ func (m *DJSON) Parse(doc string) *DJSON {
 
 	var err error
 
+	if (tdoc[0] == '{' || tdoc[0] == '[') && (m.ordered || m.lossless) {
+		dec := NewDecoder(strings.NewReader(tdoc)).SetOrdered(m.ordered)
+		if m.lossless {
+			dec.SetLosslessNumbers(true)
+		}
+
+		ret, err := dec.Decode()
+		if err == nil && (ret.IsObject() || ret.IsArray()) {
+			m.Object = ret.Object
+			m.Array = ret.Array
+			m.JsonType = ret.JsonType
+		}
+		return m
+	}
+
 	if tdoc[0] == '{' {
 		m.Object, err = ParseToObject(tdoc)
 		if err == nil {
@@ -167,7 +186,9 @@ func (m *DJSON) Parse(doc string) *DJSON {
 			m.Bool, _ = gov.ToBoolean(tdoc)
 		} else {
 			if gov.IsNumeric(tdoc) {
-				if gov.IsInt(tdoc) {
+				if m.lossless || IsLosslessNumbers() {
+					m.Put(json.Number(tdoc))
+				} else if gov.IsInt(tdoc) {
 					m.Int, _ = strconv.ParseInt(tdoc, 10, 64)
 					m.JsonType = JSON_INT
 				} else {
@@ -184,6 +205,64 @@ func (m *DJSON) Parse(doc string) *DJSON {
 	return m
 }
 
+// ParseWithError parses doc as strict JSON. Unlike Parse, a malformed
+// document is reported as *ParseError and m is left unchanged.
+
+func (m *DJSON) ParseWithError(doc string) (*DJSON, error) {
+	ret, err := parseDocument(doc, m.ordered, m.lossless)
+	if err != nil {
+		return m, err
+	}
+
+	ret.ordered = m.ordered
+	ret.lossless = m.lossless
+	*m = *ret
+	return m, nil
+}
+
+// SetOrdered selects insertion-order-preserving objects for this document.
+// It applies to objects already in the document and to those created later
+// by Parse, ParseWithError, SetAsObject and Put.
+
+func (m *DJSON) SetOrdered(on bool) *DJSON {
+	m.ordered = on
+	setElementOrdered(m.GetAsInterface(), on)
+	return m
+}
+
+func setElementOrdered(element interface{}, on bool) {
+	switch t := element.(type) {
+	case *DO:
+		t.SetOrdered(on)
+		for _, v := range t.Map {
+			setElementOrdered(v, on)
+		}
+	case *DA:
+		for idx := range t.Element {
+			setElementOrdered(t.Element[idx], on)
+		}
+	}
+}
+
+func (m *DJSON) newObject() *DO {
+	obj := NewObject()
+	if m.ordered {
+		obj.SetOrdered(true)
+	}
+	if m.lossless {
+		obj.lossless = true
+	}
+	return obj
+}
+
+func (m *DJSON) newArray() *DA {
+	arr := NewArray()
+	if m.lossless {
+		arr.lossless = true
+	}
+	return arr
+}
+
 func (m *DJSON) Put(v ...interface{}) *DJSON {
 
 	if IsEmptyArg(v) {
@@ -220,9 +299,25 @@ func (m *DJSON) Put(v ...interface{}) *DJSON {
 		return m
 	}
 
+	if n, ok := v[0].(json.Number); ok {
+		nJson, _ := elementToDJSON(n)
+		if m.JsonType == JSON_NULL || m.JsonType == nJson.JsonType {
+			m.Int = nJson.Int
+			m.Float = nJson.Float
+			m.number = n
+			m.Array = nil
+			m.Object = nil
+			m.JsonType = nJson.JsonType
+		} else {
+			m.PutAsArray(n) // best effort
+		}
+		return m
+	}
+
 	if IsInTypes(v[0], "int", "uint", "int8", "uint8", "int16", "uint16", "int32", "uint32", "int64", "uint64") {
 		if m.JsonType == JSON_NULL || m.JsonType == JSON_INT {
 			m.Int, _ = getIntBase(v[0])
+			m.number = ""
 			m.Array = nil
 			m.Object = nil
 			m.JsonType = JSON_INT
@@ -235,6 +330,7 @@ func (m *DJSON) Put(v ...interface{}) *DJSON {
 	if IsInTypes(v[0], "float32", "float64") {
 		if m.JsonType == JSON_NULL || m.JsonType == JSON_FLOAT {
 			m.Float, _ = getFloatBase(v[0])
+			m.number = ""
 			m.Array = nil
 			m.Object = nil
 			m.JsonType = JSON_FLOAT
@@ -355,7 +451,7 @@ func (m *DJSON) Put(v ...interface{}) *DJSON {
 
 func (m *DJSON) PutAsArray(value ...interface{}) *DJSON {
 	if m.JsonType == JSON_NULL {
-		m.Array = NewArray()
+		m.Array = m.newArray()
 		m.JsonType = JSON_ARRAY
 	}
 
@@ -368,7 +464,7 @@ func (m *DJSON) PutAsArray(value ...interface{}) *DJSON {
 
 func (m *DJSON) PutAsObject(key string, value interface{}) *DJSON {
 	if m.JsonType == JSON_NULL {
-		m.Object = NewObject()
+		m.Object = m.newObject()
 		m.JsonType = JSON_OBJECT
 	}
 
@@ -404,8 +500,14 @@ func (m *DJSON) GetAsInterface(key ...interface{}) interface{} {
 		case JSON_BOOL:
 			return m.Bool
 		case JSON_INT:
+			if m.number != "" {
+				return m.number
+			}
 			return m.Int
 		case JSON_FLOAT:
+			if m.number != "" {
+				return m.number
+			}
 			return m.Float
 		case JSON_OBJECT:
 			return m.Object
@@ -440,7 +542,6 @@ func (m *DJSON) Get(key ...interface{}) (*DJSON, bool) {
 		return m, true
 	} else {
 
-		r := NewDJSON()
 		var element interface{}
 		var retOk bool
 
@@ -459,47 +560,81 @@ func (m *DJSON) Get(key ...interface{}) (*DJSON, bool) {
 			return nil, false
 		}
 
-		eVal := reflect.ValueOf(element)
+		return elementToDJSON(element)
+	}
+}
 
-		switch t := element.(type) {
-		case nil:
-			r.JsonType = JSON_NULL
-		case string:
-			r.String = t
-			r.JsonType = JSON_STRING
-		case bool:
-			r.Bool = t
-			r.JsonType = JSON_BOOL
-		case uint8, uint16, uint32, uint64, uint:
-			intVal := int64(eVal.Uint())
-			r.Int = intVal
-			r.JsonType = JSON_INT
-		case int8, int16, int32, int64, int:
-			intVal := eVal.Int()
-			r.Int = intVal
-			r.JsonType = JSON_INT
-		case float32, float64:
-			floatVal := eVal.Float()
-			r.Float = floatVal
-			r.JsonType = JSON_FLOAT
-		case DA:
-			r.Array = &t
-			r.JsonType = JSON_ARRAY
-		case DO:
-			r.Object = &t
-			r.JsonType = JSON_OBJECT
-		case *DA:
-			r.Array = t
-			r.JsonType = JSON_ARRAY
-		case *DO:
-			r.Object = t
-			r.JsonType = JSON_OBJECT
-		default:
-			return nil, false
-		}
+func elementToDJSON(element interface{}) (*DJSON, bool) {
+	r := NewDJSON()
+	eVal := reflect.ValueOf(element)
 
-		return r, true
+	switch t := element.(type) {
+	case nil:
+		r.JsonType = JSON_NULL
+	case string:
+		r.String = t
+		r.JsonType = JSON_STRING
+	case bool:
+		r.Bool = t
+		r.JsonType = JSON_BOOL
+	case uint8, uint16, uint32, uint64, uint:
+		intVal := int64(eVal.Uint())
+		r.Int = intVal
+		r.JsonType = JSON_INT
+	case int8, int16, int32, int64, int:
+		intVal := eVal.Int()
+		r.Int = intVal
+		r.JsonType = JSON_INT
+	case float32, float64:
+		floatVal := eVal.Float()
+		r.Float = floatVal
+		r.JsonType = JSON_FLOAT
+	case json.Number:
+		r.number = t
+		r.Int, _ = getIntBase(t)
+		r.Float, _ = getFloatBase(t)
+		r.JsonType = elementType(t)
+	case DA:
+		r.Array = &t
+		r.JsonType = JSON_ARRAY
+	case DO:
+		r.Object = &t
+		r.JsonType = JSON_OBJECT
+	case *DA:
+		r.Array = t
+		r.JsonType = JSON_ARRAY
+	case *DO:
+		r.Object = t
+		r.JsonType = JSON_OBJECT
+	default:
+		return nil, false
+	}
+
+	return r, true
+}
+
+func elementType(element interface{}) int {
+	switch t := element.(type) {
+	case string:
+		return JSON_STRING
+	case bool:
+		return JSON_BOOL
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return JSON_INT
+	case float32, float64:
+		return JSON_FLOAT
+	case json.Number:
+		if isIntLiteral(t) {
+			return JSON_INT
+		}
+		return JSON_FLOAT
+	case DA, *DA:
+		return JSON_ARRAY
+	case DO, *DO:
+		return JSON_OBJECT
 	}
+
+	return JSON_NULL
 }
 
 // The DJSON as return shared Object.
@@ -817,12 +952,18 @@ func (m *DJSON) ToString() string {
 	case JSON_STRING:
 		return m.String
 	case JSON_INT:
+		if m.number != "" {
+			return string(m.number)
+		}
 		intStr, ok := getStringBase(m.Int)
 		if !ok {
 			return ""
 		}
 		return intStr
 	case JSON_FLOAT:
+		if m.number != "" {
+			return string(m.number)
+		}
 		floatStr, ok := getStringBase(m.Float)
 		if !ok {
 			return ""
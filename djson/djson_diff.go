@@ -0,0 +1,302 @@
+package djson
+
+import (
+	"sort"
+	"strconv"
+)
+
+const (
+	DIFF_ARRAY_ORDERED = iota
+	DIFF_ARRAY_SET
+)
+
+// DiffOptions controls how arrays are compared. In DIFF_ARRAY_SET mode,
+// elements are matched by KeyField (objects) or by value. KeyFields
+// overrides the key per array, keyed by the JSON pointer of the array in
+// the source document; "*" matches any array index in the pointer. If
+// several patterns match, the one with the fewest "*" wins.
+
+type DiffOptions struct {
+	ArrayMode int
+	KeyField  string
+	KeyFields map[string]string
+}
+
+type DiffEntry struct {
+	Op   string
+	Path string
+	Old  *DJSON
+	New  *DJSON
+}
+
+type differ struct {
+	opts    *DiffOptions
+	entries []*DiffEntry
+}
+
+func newDiffer(opts []*DiffOptions) *differ {
+	d := &differ{
+		opts:    &DiffOptions{},
+		entries: make([]*DiffEntry, 0),
+	}
+
+	if len(opts) > 0 && opts[0] != nil {
+		d.opts = opts[0]
+	}
+
+	return d
+}
+
+func appendPath(path []interface{}, token interface{}) []interface{} {
+	nPath := make([]interface{}, len(path), len(path)+1)
+	copy(nPath, path)
+	return append(nPath, token)
+}
+
+func (d *differ) add(op string, path []interface{}, old, new interface{}) {
+	entry := &DiffEntry{
+		Op:   op,
+		Path: BuildPointer(path...),
+	}
+
+	if op != "add" {
+		entry.Old, _ = elementToDJSON(cloneElement(old))
+	}
+
+	if op != "remove" {
+		entry.New, _ = elementToDJSON(cloneElement(new))
+	}
+
+	d.entries = append(d.entries, entry)
+}
+
+// keyFieldFor returns the key field of the array at path. If several
+// KeyFields patterns match, the most specific wins: the one with fewer "*",
+// then the one whose first "*" comes later, then the smaller pattern.
+
+func (d *differ) keyFieldFor(path []interface{}) (string, bool) {
+	if d.opts.ArrayMode != DIFF_ARRAY_SET {
+		return "", false
+	}
+
+	best, bestWild := "", []bool(nil)
+
+	for pattern := range d.opts.KeyFields {
+		tokens, err := ParsePointer(pattern)
+		if err != nil || len(tokens) != len(path) {
+			continue
+		}
+
+		matched := true
+		wild := make([]bool, len(tokens))
+		for idx := range tokens {
+			switch t := path[idx].(type) {
+			case int:
+				wild[idx] = tokens[idx] == "*"
+				matched = wild[idx] || tokens[idx] == strconv.Itoa(t)
+			case string:
+				matched = tokens[idx] == t
+			}
+			if !matched {
+				break
+			}
+		}
+
+		if matched && (bestWild == nil || moreSpecific(wild, bestWild, pattern, best)) {
+			best, bestWild = pattern, wild
+		}
+	}
+
+	if bestWild != nil {
+		return d.opts.KeyFields[best], true
+	}
+
+	return d.opts.KeyField, true
+}
+
+func moreSpecific(a, b []bool, aPattern, bPattern string) bool {
+	aCount, bCount := 0, 0
+	for idx := range a {
+		if a[idx] {
+			aCount++
+		}
+		if b[idx] {
+			bCount++
+		}
+	}
+	if aCount != bCount {
+		return aCount < bCount
+	}
+
+	for idx := range a {
+		if a[idx] != b[idx] {
+			return b[idx]
+		}
+	}
+
+	return aPattern < bPattern
+}
+
+func (d *differ) diff(path []interface{}, a, b interface{}) {
+	aType := elementType(a)
+	bType := elementType(b)
+
+	switch {
+	case aType == JSON_OBJECT && bType == JSON_OBJECT:
+		aObj, _ := elementToDJSON(a)
+		bObj, _ := elementToDJSON(b)
+		d.diffObject(path, aObj.Object, bObj.Object)
+	case aType == JSON_ARRAY && bType == JSON_ARRAY:
+		aArr, _ := elementToDJSON(a)
+		bArr, _ := elementToDJSON(b)
+		if key, ok := d.keyFieldFor(path); ok {
+			d.diffArraySet(path, aArr.Array, bArr.Array, key)
+		} else {
+			d.diffArrayOrdered(path, aArr.Array, bArr.Array)
+		}
+	default:
+		if !elementEqual(a, b) {
+			d.add("replace", path, a, b)
+		}
+	}
+}
+
+func (d *differ) diffObject(path []interface{}, a, b *DO) {
+	keys := make([]string, 0, len(a.Map)+len(b.Map))
+	for k := range a.Map {
+		keys = append(keys, k)
+	}
+	for k := range b.Map {
+		if !a.HasKey(k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		av, aok := a.Map[k]
+		bv, bok := b.Map[k]
+
+		switch {
+		case aok && !bok:
+			d.add("remove", appendPath(path, k), av, nil)
+		case !aok && bok:
+			d.add("add", appendPath(path, k), nil, bv)
+		default:
+			d.diff(appendPath(path, k), av, bv)
+		}
+	}
+}
+
+func (d *differ) diffArrayOrdered(path []interface{}, a, b *DA) {
+	common := a.Size()
+	if b.Size() < common {
+		common = b.Size()
+	}
+
+	for idx := 0; idx < common; idx++ {
+		d.diff(appendPath(path, idx), a.Element[idx], b.Element[idx])
+	}
+
+	for idx := a.Size() - 1; idx >= common; idx-- {
+		d.add("remove", appendPath(path, idx), a.Element[idx], nil)
+	}
+
+	for idx := common; idx < b.Size(); idx++ {
+		d.add("add", appendPath(path, idx), nil, b.Element[idx])
+	}
+}
+
+func arrayElementKey(v interface{}, key string) (string, bool) {
+	obj, ok := v.(*DO)
+	if !ok || !obj.HasKey(key) {
+		return "", false
+	}
+
+	return obj.GetAsString2(key)
+}
+
+func (d *differ) diffArraySet(path []interface{}, a, b *DA, key string) {
+	matchedA := make([]bool, a.Size())
+	matchedB := make([]bool, b.Size())
+
+	for bi := range b.Element {
+		bKey, bKeyed := "", false
+		if key != "" {
+			bKey, bKeyed = arrayElementKey(b.Element[bi], key)
+		}
+
+		for ai := range a.Element {
+			if matchedA[ai] {
+				continue
+			}
+
+			if bKeyed {
+				if aKey, ok := arrayElementKey(a.Element[ai], key); !ok || aKey != bKey {
+					continue
+				}
+				d.diff(appendPath(path, ai), a.Element[ai], b.Element[bi])
+			} else if !elementEqual(a.Element[ai], b.Element[bi]) {
+				continue
+			}
+
+			matchedA[ai] = true
+			matchedB[bi] = true
+			break
+		}
+	}
+
+	for ai := a.Size() - 1; ai >= 0; ai-- {
+		if !matchedA[ai] {
+			d.add("remove", appendPath(path, ai), a.Element[ai], nil)
+		}
+	}
+
+	for bi := range b.Element {
+		if !matchedB[bi] {
+			d.add("add", appendPath(path, "-"), nil, b.Element[bi])
+		}
+	}
+}
+
+func diffEntriesToPatch(entries []*DiffEntry) *DJSON {
+	patch := NewDJSON(JSON_ARRAY)
+
+	for _, entry := range entries {
+		op := NewObject()
+		op.Put("op", entry.Op)
+		op.Put("path", entry.Path)
+		if entry.New != nil {
+			op.Put("value", entry.New.GetAsInterface())
+		}
+		patch.PutAsArray(op)
+	}
+
+	return patch
+}
+
+// DiffEntries returns the changes from m to t with old and new values.
+
+func (m *DJSON) DiffEntries(t *DJSON, opts ...*DiffOptions) []*DiffEntry {
+	d := newDiffer(opts)
+	d.diff([]interface{}{}, m.GetAsInterface(), t.GetAsInterface())
+	return d.entries
+}
+
+// Diff returns a JSON Patch document that turns m into t.
+
+func (m *DJSON) Diff(t *DJSON, opts ...*DiffOptions) *DJSON {
+	return diffEntriesToPatch(m.DiffEntries(t, opts...))
+}
+
+func (m *DO) Diff(t *DO, opts ...*DiffOptions) *DJSON {
+	d := newDiffer(opts)
+	d.diff([]interface{}{}, m, t)
+	return diffEntriesToPatch(d.entries)
+}
+
+func (m *DA) Diff(t *DA, opts ...*DiffOptions) *DJSON {
+	d := newDiffer(opts)
+	d.diff([]interface{}{}, m, t)
+	return diffEntriesToPatch(d.entries)
+}
@@ -0,0 +1,140 @@
+package djson
+
+import (
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	aJson := NewDJSON().Parse(`{
+		"name": "Ricardo Longa",
+		"idade": 28,
+		"skills": ["Golang", "Android", "Java"],
+		"address": {"city": "Seoul", "zip": "04524"},
+		"removed": true
+	}`)
+
+	bJson := NewDJSON().Parse(`{
+		"name": "Ricardo Longa",
+		"idade": 29,
+		"skills": ["Golang", "Kotlin"],
+		"address": {"city": "Busan", "zip": "04524", "country": "KR"},
+		"note": null
+	}`)
+
+	patch := aJson.Diff(bJson)
+
+	expected := NewDJSON().Parse(`[
+		{"op": "replace", "path": "/address/city", "value": "Busan"},
+		{"op": "add", "path": "/address/country", "value": "KR"},
+		{"op": "replace", "path": "/idade", "value": 29},
+		{"op": "add", "path": "/note", "value": null},
+		{"op": "remove", "path": "/removed"},
+		{"op": "replace", "path": "/skills/1", "value": "Kotlin"},
+		{"op": "remove", "path": "/skills/2"}
+	]`)
+
+	if !patch.Equal(expected) {
+		t.Fatal(patch.ToString())
+	}
+
+	cJson := aJson.Clone()
+	if err := cJson.ApplyPatch(patch); err != nil {
+		t.Fatal(err)
+	}
+
+	if !cJson.Equal(bJson) {
+		t.Fatal(cJson.ToString())
+	}
+
+	entries := aJson.DiffEntries(bJson)
+	if len(entries) != 7 || entries[2].Old.GetAsInt() != 28 || entries[2].New.GetAsInt() != 29 {
+		t.Fatal(entries)
+	}
+
+	if entries[4].Op != "remove" || entries[4].New != nil || !entries[4].Old.GetAsBool() {
+		t.Fatal(entries[4])
+	}
+
+	if len(aJson.Diff(aJson.Clone()).Array.Element) != 0 {
+		t.Fatal("identical documents must not differ")
+	}
+}
+
+func TestDiffArraySet(t *testing.T) {
+	aJson := NewDJSON().Parse(`{
+		"items": [
+			{"id": "a", "qty": 1},
+			{"id": "b", "qty": 2},
+			{"id": "c", "qty": 3}
+		],
+		"tags": ["x", "y"]
+	}`)
+
+	bJson := NewDJSON().Parse(`{
+		"items": [
+			{"id": "c", "qty": 3},
+			{"id": "d", "qty": 4},
+			{"id": "a", "qty": 5}
+		],
+		"tags": ["y", "x"]
+	}`)
+
+	patch := aJson.Diff(bJson, &DiffOptions{
+		ArrayMode: DIFF_ARRAY_SET,
+		KeyFields: map[string]string{"/items": "id"},
+	})
+
+	expected := NewDJSON().Parse(`[
+		{"op": "replace", "path": "/items/0/qty", "value": 5},
+		{"op": "remove", "path": "/items/1"},
+		{"op": "add", "path": "/items/-", "value": {"id": "d", "qty": 4}}
+	]`)
+
+	if !patch.Equal(expected) {
+		t.Fatal(patch.ToString())
+	}
+
+	cJson := aJson.Clone()
+	if err := cJson.ApplyPatch(patch); err != nil {
+		t.Fatal(err)
+	}
+
+	items, _ := cJson.GetAsArray("items")
+	if items.Length() != 3 || items.Find("id", "a").GetAsInt("qty") != 5 || items.Find("id", "d") == nil {
+		t.Fatal(cJson.ToString())
+	}
+
+	aArr, _ := aJson.GetAsArray("items")
+	bArr, _ := bJson.GetAsArray("items")
+	if aArr.Array.Diff(bArr.Array).Length() != 6 {
+		t.Fatal("ordered diff must report every position")
+	}
+}
+
+func TestDiffKeyFieldsSpecific(t *testing.T) {
+	aJson := NewDJSON().Parse(`{"groups": [{"name": "x", "items": [{"id": 1, "sku": "a", "qty": 1}]}, {"name": "y", "items": [{"id": 2, "sku": "b", "qty": 1}]}]}`)
+	bJson := NewDJSON().Parse(`{"groups": [{"name": "x", "items": [{"id": 1, "sku": "c", "qty": 2}]}, {"name": "y", "items": [{"id": 3, "sku": "b", "qty": 2}]}]}`)
+
+	opts := &DiffOptions{
+		ArrayMode: DIFF_ARRAY_SET,
+		KeyField:  "name",
+		KeyFields: map[string]string{
+			"/groups/*/items": "id",
+			"/groups/1/items": "sku",
+		},
+	}
+
+	expected := NewDJSON().Parse(`[
+		{"op": "replace", "path": "/groups/0/items/0/qty", "value": 2},
+		{"op": "replace", "path": "/groups/0/items/0/sku", "value": "c"},
+		{"op": "replace", "path": "/groups/1/items/0/id", "value": 3},
+		{"op": "replace", "path": "/groups/1/items/0/qty", "value": 2}
+	]`)
+
+	// the map is ranged in random order; the result must not depend on it
+	for i := 0; i < 20; i++ {
+		if patch := aJson.Diff(bJson, opts); !patch.Equal(expected) {
+			t.Fatal(patch.ToString())
+		}
+	}
+}
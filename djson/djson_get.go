@@ -0,0 +1,188 @@
+package djson
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+const (
+	COERCE_STRICT = iota
+	COERCE_LENIENT
+)
+
+// Get returns the value at path converted to T. Paths use the same syntax
+// as GetAsIntPath, and an empty path is the document itself. With
+// COERCE_LENIENT, numeric strings convert to numbers, numbers and bools to
+// strings, and integral floats to integers. Failures are *FieldError.
+
+func Get[T any](m *DJSON, path string, coerce ...int) (T, error) {
+	var ret T
+
+	tokens := PathTokenizer(path)
+
+	element, failed := getPathElement(m.GetAsInterface(), tokens)
+	if failed >= 0 {
+		return ret, &FieldError{
+			Path:   BuildPath(tokens[:failed+1]...),
+			GoType: reflect.TypeOf(ret),
+			Err:    PathNotFoundError,
+		}
+	}
+
+	lenient := len(coerce) > 0 && coerce[0] == COERCE_LENIENT
+
+	if err := coerceValue(tokens, element, reflect.ValueOf(&ret).Elem(), lenient); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return ret, nil
+}
+
+// GetOr is like Get but returns def on any failure.
+
+func GetOr[T any](m *DJSON, path string, def T, coerce ...int) T {
+	ret, err := Get[T](m, path, coerce...)
+	if err != nil {
+		return def
+	}
+	return ret
+}
+
+func GetSlice[T any](m *DJSON, path string, coerce ...int) ([]T, error) {
+	return Get[[]T](m, path, coerce...)
+}
+
+// getPathElement returns the element at tokens, or the index of the first
+// token that could not be resolved.
+
+func getPathElement(root interface{}, tokens []interface{}) (interface{}, int) {
+	cur := root
+
+	for idx := range tokens {
+		switch tkey := tokens[idx].(type) {
+		case string:
+			obj, ok := cur.(*DO)
+			if !ok {
+				return nil, idx
+			}
+			if cur, ok = obj.Map[tkey]; !ok {
+				return nil, idx
+			}
+		case int:
+			arr, ok := cur.(*DA)
+			if !ok || tkey < 0 || tkey >= arr.Size() {
+				return nil, idx
+			}
+			cur = arr.Element[tkey]
+		default:
+			return nil, idx
+		}
+	}
+
+	return cur, -1
+}
+
+func coerceValue(path []interface{}, element interface{}, v reflect.Value, lenient bool) error {
+	t := v.Type()
+
+	if element == nil {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+			v.Set(reflect.Zero(t))
+			return nil
+		}
+		return &FieldError{
+			Path:     BuildPath(path...),
+			GoType:   t,
+			JsonType: "null",
+			Err:      NullValueError,
+		}
+	}
+
+	if !lenient {
+		return unmarshalValue(path, element, v, false)
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		arr, ok := element.(*DA)
+		if !ok || t.Elem().Kind() == reflect.Uint8 {
+			break
+		}
+
+		slice := reflect.MakeSlice(t, arr.Size(), arr.Size())
+		for idx := range arr.Element {
+			if err := coerceValue(appendPath(path, idx), arr.Element[idx], slice.Index(idx), lenient); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	case reflect.Ptr:
+		if t.Elem().Kind() != reflect.Struct {
+			if v.IsNil() {
+				v.Set(reflect.New(t.Elem()))
+			}
+			return coerceValue(path, element, v.Elem(), lenient)
+		}
+	}
+
+	return unmarshalValue(path, lenientElement(element, t.Kind()), v, false)
+}
+
+// lenientElement converts element towards kind where that loses nothing
+// the caller asked to keep; anything else is passed through unchanged.
+
+func lenientElement(element interface{}, kind reflect.Kind) interface{} {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if b, ok := element.(bool); ok {
+			if b {
+				return int64(1)
+			}
+			return int64(0)
+		}
+		if s, ok := element.(string); ok {
+			if !isNumberLiteral(strings.TrimSpace(s)) {
+				return element
+			}
+			element = json.Number(strings.TrimSpace(s))
+		}
+		if elementType(element) == JSON_FLOAT {
+			if b, ok := getBigIntBase(element); ok {
+				return json.Number(b.String())
+			}
+		}
+		return element
+	case reflect.Float32, reflect.Float64:
+		if s, ok := element.(string); ok && isNumberLiteral(strings.TrimSpace(s)) {
+			return json.Number(strings.TrimSpace(s))
+		}
+	case reflect.String:
+		switch elementType(element) {
+		case JSON_INT, JSON_FLOAT:
+			if s, ok := getDecimalBase(element); ok {
+				return s
+			}
+		case JSON_BOOL:
+			s, _ := getStringBase(element)
+			return s
+		}
+	case reflect.Bool:
+		switch elementType(element) {
+		case JSON_STRING:
+			if b, ok := getBoolBase(element); ok {
+				return b
+			}
+		case JSON_INT, JSON_FLOAT:
+			if d, ok := decimalOf(element); ok {
+				return d.sign() != 0
+			}
+		}
+	}
+
+	return element
+}
@@ -0,0 +1,85 @@
+package djson
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetGeneric(t *testing.T) {
+	aJson := NewDJSON().Parse(`{
+		"user": {"name": "kim", "age": 42, "score": "97.5", "active": "true", "level": 3.0},
+		"ids": [1, 2, "3"],
+		"tags": ["a", "b"],
+		"empty": null
+	}`)
+
+	name, err := Get[string](aJson, `["user"]["name"]`)
+	if err != nil || name != "kim" {
+		t.Fatal(name, err)
+	}
+
+	age, err := Get[int32](aJson, `["user"]["age"]`)
+	if err != nil || age != 42 {
+		t.Fatal(age, err)
+	}
+
+	if _, err := Get[float64](aJson, `["user"]["score"]`); !errors.Is(err, TypeMismatchError) {
+		t.Fatal(err)
+	}
+
+	score, err := Get[float64](aJson, `["user"]["score"]`, COERCE_LENIENT)
+	if err != nil || score != 97.5 {
+		t.Fatal(score, err)
+	}
+
+	if active := GetOr(aJson, `["user"]["active"]`, false, COERCE_LENIENT); !active {
+		t.Fatal("expected active")
+	}
+
+	if level, err := Get[uint8](aJson, `["user"]["level"]`, COERCE_LENIENT); err != nil || level != 3 {
+		t.Fatal(level, err)
+	}
+
+	if ageStr, err := Get[string](aJson, `["user"]["age"]`, COERCE_LENIENT); err != nil || ageStr != "42" {
+		t.Fatal(ageStr, err)
+	}
+
+	ptr, err := Get[*int64](aJson, `["empty"]`)
+	if err != nil || ptr != nil {
+		t.Fatal(ptr, err)
+	}
+
+	if _, err := Get[int64](aJson, `["empty"]`); !errors.Is(err, NullValueError) {
+		t.Fatal(err)
+	}
+
+	var fieldErr *FieldError
+
+	_, err = Get[string](aJson, `["user"]["address"]["city"]`)
+	if !errors.As(err, &fieldErr) || !errors.Is(err, PathNotFoundError) || fieldErr.Path != `["user"]["address"]` {
+		t.Fatal(err)
+	}
+
+	if _, err := GetSlice[int](aJson, `["ids"]`); !errors.As(err, &fieldErr) || fieldErr.Path != `["ids"][2]` {
+		t.Fatal(err)
+	}
+
+	ids, err := GetSlice[int](aJson, `["ids"]`, COERCE_LENIENT)
+	if err != nil || len(ids) != 3 || ids[2] != 3 {
+		t.Fatal(ids, err)
+	}
+
+	tags, err := GetSlice[string](aJson, `["tags"]`)
+	if err != nil || len(tags) != 2 || tags[1] != "b" {
+		t.Fatal(tags, err)
+	}
+
+	if GetOr(aJson, `["user"]["missing"]`, int64(-1)) != -1 {
+		t.Fatal("expected default")
+	}
+
+	user, err := Get[*DJSON](aJson, `["user"]`)
+	if err != nil || user.GetAsString("name") != "kim" {
+		t.Fatal(user, err)
+	}
+}
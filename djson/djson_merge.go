@@ -0,0 +1,168 @@
+package djson
+
+const (
+	MERGE_ARRAY_REPLACE = iota
+	MERGE_ARRAY_APPEND
+	MERGE_ARRAY_UNION
+)
+
+// MergeOptions controls deep merging. With NullDeletes a null in the source
+// removes the key from the destination (RFC 7386). OnConflict is called when
+// a destination value would be overwritten by a different source value; the
+// returned value is stored, and returning nil keeps the destination value.
+
+type MergeOptions struct {
+	ArrayMode   int
+	NullDeletes bool
+	OnConflict  func(path string, dst *DJSON, src *DJSON) *DJSON
+}
+
+func resolveMergeConflict(path []interface{}, dst interface{}, candidate interface{}, opts *MergeOptions) interface{} {
+	if opts.OnConflict == nil {
+		return candidate
+	}
+
+	dJson, _ := elementToDJSON(dst)
+	sJson, _ := elementToDJSON(candidate)
+
+	ret := opts.OnConflict(BuildPointer(path...), dJson, sJson)
+	if ret == nil {
+		return dst
+	}
+
+	return cloneElement(ret.GetAsInterface())
+}
+
+func mergeArray(dst *DA, src *DA, opts *MergeOptions) {
+	for idx := range src.Element {
+		if opts.ArrayMode == MERGE_ARRAY_UNION {
+			found := false
+			for didx := range dst.Element {
+				if elementEqual(dst.Element[didx], src.Element[idx]) {
+					found = true
+					break
+				}
+			}
+			if found {
+				continue
+			}
+		}
+
+		dst.PushBack(cloneElement(src.Element[idx]))
+	}
+}
+
+func mergeElement(path []interface{}, dst interface{}, exists bool, src interface{}, opts *MergeOptions) interface{} {
+	if sObj, ok := src.(*DO); ok {
+		if dObj, ok := dst.(*DO); ok {
+			mergeObject(path, dObj, sObj, opts)
+			return dObj
+		}
+
+		nObj := NewObject()
+		mergeObject(path, nObj, sObj, opts)
+
+		if !exists {
+			return nObj
+		}
+
+		return resolveMergeConflict(path, dst, nObj, opts)
+	}
+
+	if sArr, ok := src.(*DA); ok && opts.ArrayMode != MERGE_ARRAY_REPLACE {
+		if dArr, ok := dst.(*DA); ok {
+			mergeArray(dArr, sArr, opts)
+			return dArr
+		}
+	}
+
+	if !exists {
+		return cloneElement(src)
+	}
+
+	if elementEqual(dst, src) {
+		return dst
+	}
+
+	return resolveMergeConflict(path, dst, cloneElement(src), opts)
+}
+
+func mergeObject(path []interface{}, dst *DO, src *DO, opts *MergeOptions) {
+	for _, k := range src.Keys() {
+		sv := src.Map[k]
+
+		if sv == nil && opts.NullDeletes {
+			dst.Remove(k)
+			continue
+		}
+
+		dv, exists := dst.Map[k]
+		dst.Put(k, mergeElement(appendPath(path, k), dv, exists, sv, opts))
+	}
+}
+
+func mergeOptions(opts []*MergeOptions) *MergeOptions {
+	if len(opts) > 0 && opts[0] != nil {
+		return opts[0]
+	}
+
+	return &MergeOptions{}
+}
+
+// Merge deep-merges src into m. Nested objects are merged key by key,
+// other values are overwritten unless OnConflict decides otherwise.
+
+func (m *DO) Merge(src *DO, opts ...*MergeOptions) *DO {
+	if src == nil {
+		return m
+	}
+
+	mergeObject([]interface{}{}, m, src, mergeOptions(opts))
+	return m
+}
+
+func (m *DJSON) Merge(src *DJSON, opts ...*MergeOptions) *DJSON {
+	if src == nil {
+		return m
+	}
+
+	mOpts := mergeOptions(opts)
+
+	if src.IsNull() && mOpts.NullDeletes {
+		return m
+	}
+
+	merged := mergeElement([]interface{}{}, m.GetAsInterface(), true, src.GetAsInterface(), mOpts)
+	if nJson, ok := elementToDJSON(merged); ok {
+		*m = *nJson
+	}
+
+	return m
+}
+
+// MergePatch applies a JSON Merge Patch (RFC 7386) to m.
+
+func (m *DJSON) MergePatch(patch *DJSON) *DJSON {
+	if patch == nil {
+		return m
+	}
+
+	if !patch.IsObject() {
+		*m = *patch.Clone()
+		return m
+	}
+
+	if !m.IsObject() {
+		m.SetAsObject()
+	}
+
+	m.Object.MergePatch(patch.Object)
+	return m
+}
+
+func (m *DO) MergePatch(patch *DO) *DO {
+	return m.Merge(patch, &MergeOptions{
+		ArrayMode:   MERGE_ARRAY_REPLACE,
+		NullDeletes: true,
+	})
+}
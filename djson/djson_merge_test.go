@@ -0,0 +1,82 @@
+package djson
+
+import (
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	testCases := [][3]string{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tc := range testCases {
+		target := NewDJSON().Parse(tc[0])
+		patch := NewDJSON().Parse(tc[1])
+		expected := NewDJSON().Parse(tc[2])
+
+		target.MergePatch(patch)
+
+		if !target.Equal(expected) {
+			t.Fatalf("%s + %s: expected %s, got %s", tc[0], tc[1], tc[2], target.ToString())
+		}
+	}
+}
+
+func TestMergeStrategy(t *testing.T) {
+	defaults := `{
+		"server": {"port": 8080, "hosts": ["a", "b"]},
+		"debug": false,
+		"tags": ["x"]
+	}`
+	override := `{
+		"server": {"port": 9090, "hosts": ["b", "c"], "tls": true},
+		"debug": null,
+		"tags": ["y"]
+	}`
+
+	replaced := NewDJSON().Parse(defaults).Merge(NewDJSON().Parse(override))
+	if replaced.ToString() != `{"debug":null,"server":{"hosts":["b","c"],"port":9090,"tls":true},"tags":["y"]}` {
+		t.Fatal(replaced.ToString())
+	}
+
+	appended := NewDJSON().Parse(defaults).Merge(NewDJSON().Parse(override), &MergeOptions{
+		ArrayMode:   MERGE_ARRAY_APPEND,
+		NullDeletes: true,
+	})
+	if appended.ToString() != `{"server":{"hosts":["a","b","b","c"],"port":9090,"tls":true},"tags":["x","y"]}` {
+		t.Fatal(appended.ToString())
+	}
+
+	conflicts := make([]string, 0)
+	unioned := NewDJSON().Parse(defaults).Merge(NewDJSON().Parse(override), &MergeOptions{
+		ArrayMode: MERGE_ARRAY_UNION,
+		OnConflict: func(path string, dst *DJSON, src *DJSON) *DJSON {
+			conflicts = append(conflicts, path)
+			if path == "/server/port" {
+				return dst
+			}
+			return nil
+		},
+	})
+	if unioned.ToString() != `{"debug":false,"server":{"hosts":["a","b","c"],"port":8080,"tls":true},"tags":["x","y"]}` {
+		t.Fatal(unioned.ToString())
+	}
+
+	if len(conflicts) != 2 || conflicts[0] != "/debug" || conflicts[1] != "/server/port" {
+		t.Fatal(conflicts)
+	}
+}
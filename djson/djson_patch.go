@@ -0,0 +1,256 @@
+package djson
+
+import (
+	"fmt"
+)
+
+// JSON Patch (RFC 6902)
+
+func cloneElement(v interface{}) interface{} {
+	switch t := v.(type) {
+	case *DO:
+		return t.Clone()
+	case *DA:
+		return t.Clone()
+	case DO:
+		return t.Clone()
+	case DA:
+		return t.Clone()
+	case *DJSON:
+		return t.Clone().GetAsInterface()
+	}
+
+	return v
+}
+
+func elementEqual(a, b interface{}) bool {
+	aJson, aok := elementToDJSON(a)
+	bJson, bok := elementToDJSON(b)
+
+	if !aok || !bok {
+		return false
+	}
+
+	if aJson.IsNumeric() && bJson.IsNumeric() {
+		if aJson.IsInt() && bJson.IsInt() {
+			return aJson.Int == bJson.Int
+		}
+		return aJson.GetAsFloat() == bJson.GetAsFloat()
+	}
+
+	return aJson.Equal(bJson)
+}
+
+func patchAdd(root *DJSON, tokens []string, value interface{}) error {
+	if len(tokens) == 0 {
+		nJson, ok := elementToDJSON(value)
+		if !ok {
+			return InvalidPatchError
+		}
+		*root = *nJson
+		return nil
+	}
+
+	parent, err := getPointerElement(root.GetAsInterface(), tokens[:len(tokens)-1])
+	if err != nil {
+		return err
+	}
+
+	last := tokens[len(tokens)-1]
+
+	switch t := parent.(type) {
+	case *DO:
+		t.Put(last, value)
+	case *DA:
+		idx, err := pointerIndex(last, t.Size(), true)
+		if err != nil {
+			return err
+		}
+		t.Insert(idx, value)
+	default:
+		return PointerNotFoundError
+	}
+
+	return nil
+}
+
+func patchRemove(root *DJSON, tokens []string) (interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, InvalidPatchError
+	}
+
+	parent, err := getPointerElement(root.GetAsInterface(), tokens[:len(tokens)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	last := tokens[len(tokens)-1]
+
+	switch t := parent.(type) {
+	case *DO:
+		v, ok := t.Get(last)
+		if !ok {
+			return nil, PointerNotFoundError
+		}
+		t.Remove(last)
+		return v, nil
+	case *DA:
+		idx, err := pointerIndex(last, t.Size(), false)
+		if err != nil {
+			return nil, err
+		}
+		v := t.Element[idx]
+		t.Remove(idx)
+		return v, nil
+	}
+
+	return nil, PointerNotFoundError
+}
+
+func patchReplace(root *DJSON, tokens []string, value interface{}) error {
+	if len(tokens) == 0 {
+		return patchAdd(root, tokens, value)
+	}
+
+	parent, err := getPointerElement(root.GetAsInterface(), tokens[:len(tokens)-1])
+	if err != nil {
+		return err
+	}
+
+	last := tokens[len(tokens)-1]
+
+	switch t := parent.(type) {
+	case *DO:
+		if !t.HasKey(last) {
+			return PointerNotFoundError
+		}
+		t.Put(last, value)
+	case *DA:
+		idx, err := pointerIndex(last, t.Size(), false)
+		if err != nil {
+			return err
+		}
+		t.ReplaceAt(idx, value)
+	default:
+		return PointerNotFoundError
+	}
+
+	return nil
+}
+
+func isPointerPrefix(prefix, tokens []string) bool {
+	if len(prefix) >= len(tokens) {
+		return false
+	}
+
+	for idx := range prefix {
+		if prefix[idx] != tokens[idx] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func applyPatchOperation(root *DJSON, op *DJSON) error {
+	if !op.IsObject() || !op.IsString("op") || !op.IsString("path") {
+		return InvalidPatchError
+	}
+
+	tokens, err := ParsePointer(op.GetAsString("path"))
+	if err != nil {
+		return err
+	}
+
+	var value interface{}
+	opName := op.GetAsString("op")
+
+	switch opName {
+	case "add", "replace", "test":
+		if !op.HasKey("value") {
+			return InvalidPatchError
+		}
+		value = cloneElement(op.GetAsInterface("value"))
+	}
+
+	var from []string
+	switch opName {
+	case "move", "copy":
+		if !op.IsString("from") {
+			return InvalidPatchError
+		}
+		if from, err = ParsePointer(op.GetAsString("from")); err != nil {
+			return err
+		}
+	}
+
+	switch opName {
+	case "add":
+		return patchAdd(root, tokens, value)
+	case "remove":
+		_, err := patchRemove(root, tokens)
+		return err
+	case "replace":
+		return patchReplace(root, tokens, value)
+	case "move":
+		if isPointerPrefix(from, tokens) {
+			return InvalidPatchError
+		}
+		moved, err := patchRemove(root, from)
+		if err != nil {
+			return err
+		}
+		return patchAdd(root, tokens, moved)
+	case "copy":
+		src, err := getPointerElement(root.GetAsInterface(), from)
+		if err != nil {
+			return err
+		}
+		return patchAdd(root, tokens, cloneElement(src))
+	case "test":
+		target, err := getPointerElement(root.GetAsInterface(), tokens)
+		if err != nil {
+			return err
+		}
+		if !elementEqual(target, value) {
+			return PatchTestFailedError
+		}
+		return nil
+	}
+
+	return InvalidPatchError
+}
+
+// ApplyPatch applies all operations of a JSON Patch document.
+// If any operation fails, m is left unchanged.
+
+func (m *DJSON) ApplyPatch(patch *DJSON) error {
+	if patch == nil || !patch.IsArray() {
+		return InvalidPatchError
+	}
+
+	work := m.Clone()
+
+	for idx := 0; idx < patch.Length(); idx++ {
+		op, ok := patch.Get(idx)
+		if !ok {
+			return InvalidPatchError
+		}
+
+		if err := applyPatchOperation(work, op); err != nil {
+			return fmt.Errorf("operation %d (%s %s): %w", idx, op.GetAsString("op"), op.GetAsString("path"), err)
+		}
+	}
+
+	*m = *work
+	return nil
+}
+
+func (m *DJSON) ApplyPatchString(patch string) error {
+	pJson, err := NewDJSON().ParseWithError(patch)
+	if err != nil {
+		return err
+	}
+
+	return m.ApplyPatch(pJson)
+}
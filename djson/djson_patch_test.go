@@ -0,0 +1,121 @@
+package djson
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPointer(t *testing.T) {
+	jsonDoc := `{
+		"foo": ["bar", "baz"],
+		"": 0,
+		"a/b": 1,
+		"c%d": 2,
+		"m~n": 8,
+		"k\"l": 6,
+		" ": 7
+	}`
+
+	aJson := NewDJSON().Parse(jsonDoc)
+
+	testCases := map[string]string{
+		"":       aJson.ToString(),
+		"/foo":   `["bar","baz"]`,
+		"/foo/0": "bar",
+		"/":      "0",
+		"/a~1b":  "1",
+		"/c%d":   "2",
+		"/k\"l":  "6",
+		"/ ":     "7",
+		"/m~0n":  "8",
+	}
+
+	for pointer, expected := range testCases {
+		v, ok := aJson.GetPointer(pointer)
+		if !ok {
+			t.Fatalf("%s: not found", pointer)
+		}
+		if v.ToString() != expected {
+			t.Fatalf("%s: expected %s, got %s", pointer, expected, v.ToString())
+		}
+	}
+
+	for _, pointer := range []string{"foo", "/foo/2", "/foo/01", "/foo/-", "/bar", "/m~2n"} {
+		if aJson.HasPointer(pointer) {
+			t.Fatalf("%s: must not be found", pointer)
+		}
+	}
+
+	if BuildPointer("a/b", 1, "m~n") != "/a~1b/1/m~0n" {
+		t.Fatal(BuildPointer("a/b", 1, "m~n"))
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	aJson := NewDJSON().Parse(`{
+		"biscuits": [
+			{"name": "Digestive"},
+			{"name": "Choco Leibniz"}
+		],
+		"best_biscuit": {"name": "Digestive"}
+	}`)
+
+	err := aJson.ApplyPatchString(`[
+		{"op": "add", "path": "/biscuits/1", "value": {"name": "Ginger Nut"}},
+		{"op": "add", "path": "/biscuits/-", "value": {"name": "Hobnob"}},
+		{"op": "remove", "path": "/biscuits/0"},
+		{"op": "replace", "path": "/best_biscuit/name", "value": "Hobnob"},
+		{"op": "copy", "from": "/biscuits/0", "path": "/best_biscuit/prev"},
+		{"op": "move", "from": "/biscuits/1", "path": "/cookies"},
+		{"op": "test", "path": "/cookies/name", "value": "Choco Leibniz"}
+	]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := NewDJSON().Parse(`{
+		"biscuits": [
+			{"name": "Ginger Nut"},
+			{"name": "Hobnob"}
+		],
+		"best_biscuit": {"name": "Hobnob", "prev": {"name": "Ginger Nut"}},
+		"cookies": {"name": "Choco Leibniz"}
+	}`)
+
+	if !aJson.Equal(expected) {
+		t.Fatal(aJson.ToString())
+	}
+}
+
+func TestApplyPatchRollback(t *testing.T) {
+	aJson := NewDJSON().Parse(`{"a": [1, 2, null], "b": "x"}`)
+	before := aJson.Clone()
+
+	err := aJson.ApplyPatchString(`[
+		{"op": "remove", "path": "/a/0"},
+		{"op": "replace", "path": "/b", "value": "y"},
+		{"op": "test", "path": "/b", "value": "x"}
+	]`)
+	if !errors.Is(err, PatchTestFailedError) {
+		t.Fatal(err)
+	}
+
+	if !aJson.Equal(before) {
+		t.Fatal(aJson.ToString())
+	}
+
+	err = aJson.ApplyPatchString(`[{"op": "remove", "path": "/c"}]`)
+	if !errors.Is(err, PointerNotFoundError) {
+		t.Fatal(err)
+	}
+
+	err = aJson.ApplyPatchString(`[{"op": "move", "from": "/a", "path": "/a/0"}]`)
+	if !errors.Is(err, InvalidPatchError) {
+		t.Fatal(err)
+	}
+
+	err = aJson.ApplyPatchString(`[{"op": "replace", "path": "", "value": [1]}]`)
+	if err != nil || aJson.ToString() != "[1]" {
+		t.Fatal(err, aJson.ToString())
+	}
+}
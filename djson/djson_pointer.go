@@ -0,0 +1,123 @@
+package djson
+
+import (
+	"strconv"
+	"strings"
+)
+
+// JSON Pointer (RFC 6901)
+
+func ParsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+
+	if pointer[0] != '/' {
+		return nil, InvalidPointerError
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for idx := range tokens {
+		if strings.Contains(strings.ReplaceAll(strings.ReplaceAll(tokens[idx], "~0", ""), "~1", ""), "~") {
+			return nil, InvalidPointerError
+		}
+		tokens[idx] = strings.ReplaceAll(strings.ReplaceAll(tokens[idx], "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+func EscapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func BuildPointer(tokens ...interface{}) string {
+	var sb strings.Builder
+
+	for idx := range tokens {
+		sb.WriteByte('/')
+		switch t := tokens[idx].(type) {
+		case string:
+			sb.WriteString(EscapePointerToken(t))
+		case int:
+			sb.WriteString(strconv.Itoa(t))
+		}
+	}
+
+	return sb.String()
+}
+
+func pointerIndex(token string, size int, allowEnd bool) (int, error) {
+	if allowEnd && token == "-" {
+		return size, nil
+	}
+
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, InvalidPointerError
+	}
+
+	for _, c := range token {
+		if c < '0' || c > '9' {
+			return 0, InvalidPointerError
+		}
+	}
+
+	idx, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, InvalidPointerError
+	}
+
+	if idx > size || (!allowEnd && idx == size) {
+		return 0, PointerNotFoundError
+	}
+
+	return idx, nil
+}
+
+func getPointerElement(root interface{}, tokens []string) (interface{}, error) {
+	cur := root
+
+	for _, token := range tokens {
+		switch t := cur.(type) {
+		case *DO:
+			v, ok := t.Get(token)
+			if !ok {
+				return nil, PointerNotFoundError
+			}
+			cur = v
+		case *DA:
+			idx, err := pointerIndex(token, t.Size(), false)
+			if err != nil {
+				return nil, err
+			}
+			cur = t.Element[idx]
+		default:
+			return nil, PointerNotFoundError
+		}
+	}
+
+	return cur, nil
+}
+
+func (m *DJSON) GetPointer(pointer string) (*DJSON, bool) {
+	tokens, err := ParsePointer(pointer)
+	if err != nil {
+		return nil, false
+	}
+
+	if len(tokens) == 0 {
+		return m, true
+	}
+
+	v, err := getPointerElement(m.GetAsInterface(), tokens)
+	if err != nil {
+		return nil, false
+	}
+
+	return elementToDJSON(v)
+}
+
+func (m *DJSON) HasPointer(pointer string) bool {
+	_, ok := m.GetPointer(pointer)
+	return ok
+}
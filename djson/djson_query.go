@@ -0,0 +1,753 @@
+package djson
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// JSONPath query: $, .name, ['name'], [0], [-1], [start:end:step], [*],
+// .., [a,b] and filters such as [?(@.price > 10 && @.tags)].
+
+const (
+	querySelName = iota
+	querySelWildcard
+	querySelIndex
+	querySelSlice
+	querySelFilter
+)
+
+type querySelector struct {
+	kind   int
+	name   string
+	index  int
+	slice  [3]*int
+	filter *queryExpr
+}
+
+type queryStep struct {
+	recursive bool
+	selectors []*querySelector
+}
+
+type queryExpr struct {
+	op       string // "||", "&&", "!", "==", "!=", "<", "<=", ">", ">=", "=~", "path", "literal"
+	left     *queryExpr
+	right    *queryExpr
+	absolute bool
+	steps    []*queryStep
+	literal  interface{}
+	regex    *regexp.Regexp
+}
+
+type queryNode struct {
+	value interface{}
+	path  []interface{}
+}
+
+type queryParser struct {
+	src string
+	pos int
+}
+
+func (p *queryParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s at position %d", InvalidQueryError, fmt.Sprintf(format, args...), p.pos)
+}
+
+func (p *queryParser) skipSpace() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *queryParser) peek() byte {
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+func (p *queryParser) consume(s string) bool {
+	if strings.HasPrefix(p.src[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func isQueryNameChar(c byte) bool {
+	return c == '_' || c == '$' || c == '-' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func (p *queryParser) parseName() (string, error) {
+	start := p.pos
+	for p.pos < len(p.src) && isQueryNameChar(p.src[p.pos]) {
+		p.pos++
+	}
+
+	if start == p.pos {
+		return "", p.errorf("member name expected")
+	}
+
+	return p.src[start:p.pos], nil
+}
+
+func (p *queryParser) parseString() (string, error) {
+	quote := p.peek()
+	p.pos++
+
+	var sb strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		p.pos++
+
+		if c == quote {
+			return sb.String(), nil
+		}
+
+		if c == '\\' && p.pos < len(p.src) {
+			c = p.src[p.pos]
+			p.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 't':
+				c = '\t'
+			case 'r':
+				c = '\r'
+			}
+		}
+
+		sb.WriteByte(c)
+	}
+
+	return "", p.errorf("unterminated string")
+}
+
+func (p *queryParser) parseInt() (int, bool) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+		p.pos++
+	}
+
+	v, err := strconv.Atoi(p.src[start:p.pos])
+	if err != nil {
+		p.pos = start
+		return 0, false
+	}
+
+	return v, true
+}
+
+func (p *queryParser) parseSelector() (*querySelector, error) {
+	p.skipSpace()
+
+	switch c := p.peek(); {
+	case c == '*':
+		p.pos++
+		return &querySelector{kind: querySelWildcard}, nil
+	case c == '\'' || c == '"':
+		name, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return &querySelector{kind: querySelName, name: name}, nil
+	case c == '?':
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return &querySelector{kind: querySelFilter, filter: expr}, nil
+	case c == '-' || c == ':' || (c >= '0' && c <= '9'):
+		sel := &querySelector{kind: querySelIndex}
+
+		for part := 0; part < 3; part++ {
+			p.skipSpace()
+			if v, ok := p.parseInt(); ok {
+				sel.slice[part] = &v
+			}
+			p.skipSpace()
+
+			if part == 2 || !p.consume(":") {
+				break
+			}
+			sel.kind = querySelSlice
+		}
+
+		if sel.kind == querySelIndex {
+			if sel.slice[0] == nil {
+				return nil, p.errorf("index expected")
+			}
+			sel.index = *sel.slice[0]
+		}
+
+		return sel, nil
+	}
+
+	return nil, p.errorf("invalid selector")
+}
+
+func (p *queryParser) parseBracket() ([]*querySelector, error) {
+	selectors := make([]*querySelector, 0)
+
+	for {
+		sel, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, sel)
+
+		p.skipSpace()
+		if p.consume("]") {
+			return selectors, nil
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("',' or ']' expected")
+		}
+	}
+}
+
+func (p *queryParser) parseSteps() ([]*queryStep, error) {
+	steps := make([]*queryStep, 0)
+
+	for p.pos < len(p.src) {
+		step := &queryStep{}
+
+		switch {
+		case p.consume(".."):
+			step.recursive = true
+			if p.consume("[") {
+				sels, err := p.parseBracket()
+				if err != nil {
+					return nil, err
+				}
+				step.selectors = sels
+			} else if p.consume("*") {
+				step.selectors = []*querySelector{{kind: querySelWildcard}}
+			} else {
+				name, err := p.parseName()
+				if err != nil {
+					return nil, err
+				}
+				step.selectors = []*querySelector{{kind: querySelName, name: name}}
+			}
+		case p.consume("."):
+			if p.consume("*") {
+				step.selectors = []*querySelector{{kind: querySelWildcard}}
+			} else {
+				name, err := p.parseName()
+				if err != nil {
+					return nil, err
+				}
+				step.selectors = []*querySelector{{kind: querySelName, name: name}}
+			}
+		case p.consume("["):
+			sels, err := p.parseBracket()
+			if err != nil {
+				return nil, err
+			}
+			step.selectors = sels
+		default:
+			return steps, nil
+		}
+
+		steps = append(steps, step)
+	}
+
+	return steps, nil
+}
+
+func (p *queryParser) parseOr() (*queryExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		p.skipSpace()
+		if !p.consume("||") {
+			return left, nil
+		}
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &queryExpr{op: "||", left: left, right: right}
+	}
+}
+
+func (p *queryParser) parseAnd() (*queryExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		p.skipSpace()
+		if !p.consume("&&") {
+			return left, nil
+		}
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &queryExpr{op: "&&", left: left, right: right}
+	}
+}
+
+func (p *queryParser) parseUnary() (*queryExpr, error) {
+	p.skipSpace()
+
+	if p.peek() == '!' && !strings.HasPrefix(p.src[p.pos:], "!=") {
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &queryExpr{op: "!", left: operand}, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *queryParser) parseComparison() (*queryExpr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+
+	for _, op := range []string{"==", "!=", "<=", ">=", "=~", "<", ">"} {
+		if !p.consume(op) {
+			continue
+		}
+
+		p.skipSpace()
+
+		if op == "=~" {
+			return p.parseRegex(left)
+		}
+
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		return &queryExpr{op: op, left: left, right: right}, nil
+	}
+
+	return left, nil
+}
+
+func (p *queryParser) parseRegex(left *queryExpr) (*queryExpr, error) {
+	var pattern string
+
+	switch p.peek() {
+	case '/':
+		end := p.pos + 1
+		for end < len(p.src) && p.src[end] != '/' {
+			if p.src[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(p.src) {
+			return nil, p.errorf("unterminated regular expression")
+		}
+		pattern = p.src[p.pos+1 : end]
+		p.pos = end + 1
+		if p.consume("i") {
+			pattern = "(?i)" + pattern
+		}
+	case '\'', '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		pattern = s
+	default:
+		return nil, p.errorf("regular expression expected")
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, p.errorf("%s", err.Error())
+	}
+
+	return &queryExpr{op: "=~", left: left, regex: re}, nil
+}
+
+func (p *queryParser) parseOperand() (*queryExpr, error) {
+	p.skipSpace()
+
+	switch c := p.peek(); {
+	case c == '(':
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if !p.consume(")") {
+			return nil, p.errorf("')' expected")
+		}
+		return expr, nil
+	case c == '@' || c == '$':
+		p.pos++
+		steps, err := p.parseSteps()
+		if err != nil {
+			return nil, err
+		}
+		return &queryExpr{op: "path", absolute: c == '$', steps: steps}, nil
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return &queryExpr{op: "literal", literal: s}, nil
+	case p.consume("true"):
+		return &queryExpr{op: "literal", literal: true}, nil
+	case p.consume("false"):
+		return &queryExpr{op: "literal", literal: false}, nil
+	case p.consume("null"):
+		return &queryExpr{op: "literal", literal: nil}, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		start := p.pos
+		p.pos++
+		for p.pos < len(p.src) && strings.IndexByte("0123456789.eE+-", p.src[p.pos]) >= 0 {
+			p.pos++
+		}
+		num := p.src[start:p.pos]
+		if i, err := strconv.ParseInt(num, 10, 64); err == nil {
+			return &queryExpr{op: "literal", literal: i}, nil
+		}
+		if f, err := strconv.ParseFloat(num, 64); err == nil {
+			return &queryExpr{op: "literal", literal: f}, nil
+		}
+		p.pos = start
+		return nil, p.errorf("invalid number")
+	}
+
+	return nil, p.errorf("operand expected")
+}
+
+func compileQuery(expr string) ([]*queryStep, error) {
+	p := &queryParser{src: strings.TrimSpace(expr)}
+
+	if !p.consume("$") {
+		return nil, p.errorf("query must start with '$'")
+	}
+
+	steps, err := p.parseSteps()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos != len(p.src) {
+		return nil, p.errorf("unexpected character %q", p.src[p.pos])
+	}
+
+	return steps, nil
+}
+
+func collectDescendants(node *queryNode, out []*queryNode) []*queryNode {
+	out = append(out, node)
+
+	switch t := node.value.(type) {
+	case *DO:
+		for _, k := range t.Keys() {
+			out = collectDescendants(&queryNode{value: t.Map[k], path: appendPath(node.path, k)}, out)
+		}
+	case *DA:
+		for idx := range t.Element {
+			out = collectDescendants(&queryNode{value: t.Element[idx], path: appendPath(node.path, idx)}, out)
+		}
+	}
+
+	return out
+}
+
+// normalizeSliceBound counts a negative bound from the end and clamps it
+// to [lower, upper], which RFC 9535 sets to [0, size] for a positive step
+// and to [-1, size-1] for a negative one.
+
+func normalizeSliceBound(v *int, def int, size int, lower int, upper int) int {
+	if v == nil {
+		return def
+	}
+
+	b := *v
+	if b < 0 {
+		b += size
+	}
+	if b < lower {
+		return lower
+	}
+	if b > upper {
+		return upper
+	}
+	return b
+}
+
+func (sel *querySelector) apply(node *queryNode, root interface{}, out []*queryNode) []*queryNode {
+	switch t := node.value.(type) {
+	case *DO:
+		switch sel.kind {
+		case querySelName:
+			if v, ok := t.Map[sel.name]; ok {
+				out = append(out, &queryNode{value: v, path: appendPath(node.path, sel.name)})
+			}
+		case querySelWildcard, querySelFilter:
+			for _, k := range t.Keys() {
+				child := &queryNode{value: t.Map[k], path: appendPath(node.path, k)}
+				if sel.kind == querySelWildcard || sel.filter.test(child.value, root) {
+					out = append(out, child)
+				}
+			}
+		}
+	case *DA:
+		size := t.Size()
+
+		switch sel.kind {
+		case querySelIndex:
+			idx := sel.index
+			if idx < 0 {
+				idx += size
+			}
+			if idx >= 0 && idx < size {
+				out = append(out, &queryNode{value: t.Element[idx], path: appendPath(node.path, idx)})
+			}
+		case querySelSlice:
+			step := 1
+			if sel.slice[2] != nil {
+				step = *sel.slice[2]
+			}
+
+			if step > 0 {
+				start := normalizeSliceBound(sel.slice[0], 0, size, 0, size)
+				end := normalizeSliceBound(sel.slice[1], size, size, 0, size)
+				for idx := start; idx < end; idx += step {
+					out = append(out, &queryNode{value: t.Element[idx], path: appendPath(node.path, idx)})
+				}
+			} else if step < 0 {
+				start := normalizeSliceBound(sel.slice[0], size-1, size, -1, size-1)
+				end := normalizeSliceBound(sel.slice[1], -1, size, -1, size-1)
+				for idx := start; idx > end; idx += step {
+					out = append(out, &queryNode{value: t.Element[idx], path: appendPath(node.path, idx)})
+				}
+			}
+		case querySelWildcard, querySelFilter:
+			for idx := range t.Element {
+				child := &queryNode{value: t.Element[idx], path: appendPath(node.path, idx)}
+				if sel.kind == querySelWildcard || sel.filter.test(child.value, root) {
+					out = append(out, child)
+				}
+			}
+		}
+	}
+
+	return out
+}
+
+func evalQuery(steps []*queryStep, start *queryNode, root interface{}) []*queryNode {
+	nodes := []*queryNode{start}
+
+	for _, step := range steps {
+		candidates := nodes
+		if step.recursive {
+			candidates = make([]*queryNode, 0)
+			for _, node := range nodes {
+				candidates = collectDescendants(node, candidates)
+			}
+		}
+
+		next := make([]*queryNode, 0)
+		for _, node := range candidates {
+			for _, sel := range step.selectors {
+				next = sel.apply(node, root, next)
+			}
+		}
+
+		nodes = next
+	}
+
+	return nodes
+}
+
+func (e *queryExpr) values(current interface{}, root interface{}) []interface{} {
+	switch e.op {
+	case "literal":
+		return []interface{}{e.literal}
+	case "path":
+		start := current
+		if e.absolute {
+			start = root
+		}
+
+		nodes := evalQuery(e.steps, &queryNode{value: start}, root)
+		ret := make([]interface{}, 0, len(nodes))
+		for _, node := range nodes {
+			ret = append(ret, node.value)
+		}
+		return ret
+	}
+
+	if e.test(current, root) {
+		return []interface{}{true}
+	}
+	return []interface{}{false}
+}
+
+func compareQueryValues(op string, a, b interface{}) bool {
+	aType := elementType(a)
+	bType := elementType(b)
+
+	aNum := aType == JSON_INT || aType == JSON_FLOAT
+	bNum := bType == JSON_INT || bType == JSON_FLOAT
+
+	switch op {
+	case "==":
+		return elementEqual(a, b)
+	case "!=":
+		return !elementEqual(a, b)
+	}
+
+	var cmp int
+
+	if aNum && bNum {
+		af, _ := getFloatBase(a)
+		bf, _ := getFloatBase(b)
+		switch {
+		case af < bf:
+			cmp = -1
+		case af > bf:
+			cmp = 1
+		}
+	} else if aType == JSON_STRING && bType == JSON_STRING {
+		cmp = strings.Compare(a.(string), b.(string))
+	} else {
+		return false
+	}
+
+	switch op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+
+	return false
+}
+
+func (e *queryExpr) test(current interface{}, root interface{}) bool {
+	switch e.op {
+	case "||":
+		return e.left.test(current, root) || e.right.test(current, root)
+	case "&&":
+		return e.left.test(current, root) && e.right.test(current, root)
+	case "!":
+		return !e.left.test(current, root)
+	case "path":
+		return len(e.values(current, root)) > 0
+	case "literal":
+		b, ok := getBoolBase(e.literal)
+		return ok && b
+	case "=~":
+		for _, v := range e.left.values(current, root) {
+			if s, ok := v.(string); ok && e.regex.MatchString(s) {
+				return true
+			}
+		}
+		return false
+	}
+
+	lefts := e.left.values(current, root)
+	rights := e.right.values(current, root)
+
+	for _, l := range lefts {
+		for _, r := range rights {
+			if compareQueryValues(e.op, l, r) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func BuildPath(tokens ...interface{}) string {
+	var sb strings.Builder
+
+	for idx := range tokens {
+		switch t := tokens[idx].(type) {
+		case string:
+			t = strings.ReplaceAll(t, `\`, `\\`)
+			if strings.Contains(t, `"`) && !strings.Contains(t, `'`) {
+				sb.WriteString(`['` + t + `']`)
+			} else {
+				sb.WriteString(`["` + strings.ReplaceAll(t, `"`, `\"`) + `"]`)
+			}
+		case int:
+			sb.WriteString("[" + strconv.Itoa(t) + "]")
+		}
+	}
+
+	return sb.String()
+}
+
+func (m *DJSON) queryNodes(expr string) ([]*queryNode, error) {
+	steps, err := compileQuery(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	root := m.GetAsInterface()
+	return evalQuery(steps, &queryNode{value: root, path: []interface{}{}}, root), nil
+}
+
+// Query returns the values matched by a JSONPath expression as an array.
+// Objects and arrays in the result are shared with m.
+
+func (m *DJSON) Query(expr string) (*DJSON, error) {
+	nodes, err := m.queryNodes(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := NewDJSON(JSON_ARRAY)
+	for _, node := range nodes {
+		ret.Array.PushBack(node.value)
+	}
+
+	return ret, nil
+}
+
+// QueryPaths returns the paths matched by a JSONPath expression in the
+// bracket syntax used by UpdatePath, RemovePath and the other *Path funcs.
+
+func (m *DJSON) QueryPaths(expr string) ([]string, error) {
+	nodes, err := m.queryNodes(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		paths = append(paths, BuildPath(node.path...))
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,160 @@
+package djson
+
+import (
+	"errors"
+	"testing"
+)
+
+const queryTestDoc = `{
+	"store": {
+		"book": [
+			{"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+			{"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+			{"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99},
+			{"category": "fiction", "author": "J. R. R. Tolkien", "title": "The Lord of the Rings", "isbn": "0-395-19395-8", "price": 22.99}
+		],
+		"bicycle": {"color": "red", "price": 19.95}
+	},
+	"expensive": 10
+}`
+
+func TestQuery(t *testing.T) {
+	aJson := NewDJSON().Parse(queryTestDoc)
+
+	testCases := []struct {
+		expr     string
+		expected string
+	}{
+		{`$.store.book[*].author`, `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`},
+		{`$..author`, `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`},
+		{`$.store.*.color`, `["red"]`},
+		{`$..book[2].title`, `["Moby Dick"]`},
+		{`$..book[-1].title`, `["The Lord of the Rings"]`},
+		{`$..book[0,1].price`, `[8.95,12.99]`},
+		{`$..book[:2].price`, `[8.95,12.99]`},
+		{`$..book[1:3].price`, `[12.99,8.99]`},
+		{`$..book[::-2].price`, `[22.99,12.99]`},
+		{`$..book[?(@.isbn)].title`, `["Moby Dick","The Lord of the Rings"]`},
+		{`$..book[?(@.price < 10)].title`, `["Sayings of the Century","Moby Dick"]`},
+		{`$..book[?(@.price > $.expensive && @.category == 'fiction')].price`, `[12.99,22.99]`},
+		{`$..book[?(!@.isbn || @.author =~ /tolkien/i)].price`, `[8.95,12.99,22.99]`},
+		{`$..*[?(@.price > 19)].price`, `[19.95,22.99]`},
+		{`$['store']['bicycle']['color']`, `["red"]`},
+		{`$.store.book[4]`, `[]`},
+	}
+
+	for _, tc := range testCases {
+		ret, err := aJson.Query(tc.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.expr, err)
+		}
+
+		if ret.ToString() != tc.expected {
+			t.Fatalf("%s: expected %s, got %s", tc.expr, tc.expected, ret.ToString())
+		}
+	}
+
+	for _, expr := range []string{`store.book`, `$.store[`, `$..book[?(@.price >)]`, `$.a b`} {
+		if _, err := aJson.Query(expr); !errors.Is(err, InvalidQueryError) {
+			t.Fatalf("%s: expected invalid query, got %v", expr, err)
+		}
+	}
+}
+
+func TestQueryPaths(t *testing.T) {
+	aJson := NewDJSON().Parse(queryTestDoc)
+
+	paths, err := aJson.QueryPaths(`$..book[?(@.price > 20)].price`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(paths) != 1 || paths[0] != `["store"]["book"][3]["price"]` {
+		t.Fatal(paths)
+	}
+
+	for _, path := range paths {
+		if err := aJson.UpdatePath(path, 19.99); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if aJson.GetAsFloatPath(`["store"]["book"][3]["price"]`) != 19.99 {
+		t.Fatal(aJson.ToString())
+	}
+
+	paths, _ = aJson.QueryPaths(`$.store.book[?(@.category == "fiction")]`)
+	for idx := len(paths) - 1; idx >= 0; idx-- {
+		if err := aJson.RemovePath(paths[idx]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if books, _ := aJson.Query(`$.store.book[*]`); books.Length() != 1 {
+		t.Fatal(books.ToString())
+	}
+}
+
+func TestQuerySlice(t *testing.T) {
+	aJson := NewDJSON().Parse(`{"arr":[0,1,2,3,4]}`)
+
+	for expr, expected := range map[string]string{
+		`$.arr[:-100:-1]`:  `[4,3,2,1,0]`,
+		`$.arr[-100::-1]`:  `[]`,
+		`$.arr[100:2:-1]`:  `[4,3]`,
+		`$.arr[3::-1]`:     `[3,2,1,0]`,
+		`$.arr[-1:-3:-1]`:  `[4,3]`,
+		`$.arr[-100:100]`:  `[0,1,2,3,4]`,
+		`$.arr[4:-100:-2]`: `[4,2,0]`,
+	} {
+		ret, err := aJson.Query(expr)
+		if err != nil || ret.ToString() != expected {
+			t.Fatalf("%s: expected %s, got %s (%v)", expr, expected, ret.ToString(), err)
+		}
+	}
+}
+
+func TestBuildPathQuotes(t *testing.T) {
+	for _, key := range []string{`a"b`, `it's`, `say "it's"`, `back\slash`, `end\`, `\"`} {
+		path := BuildPath(key, 0)
+		tokens := PathTokenizer(path)
+		if len(tokens) != 2 || tokens[0] != key || tokens[1] != 0 {
+			t.Fatalf("%s: %v", path, tokens)
+		}
+
+		aJson := NewDJSON().Parse(`{}`)
+		aJson.Put(key, NewArray().Put([]string{"v"}))
+		if aJson.GetAsStringPath(path) != "v" {
+			t.Fatal(path)
+		}
+	}
+
+	if BuildPath(`a"b`) != `['a"b']` || BuildPath(`say "it's"`) != `["say \"it's\""]` {
+		t.Fatal(BuildPath(`say "it's"`))
+	}
+}
+
+func TestQueryPathsNumericKeys(t *testing.T) {
+	aJson := NewDJSON().Parse(`{"2024": {"01": 5, "x": [7]}, "0": 1, "": 2, "list": [3]}`)
+
+	paths, err := aJson.QueryPaths(`$["2024"]["01"]`)
+	if err != nil || len(paths) != 1 || paths[0] != `["2024"]["01"]` {
+		t.Fatal(paths, err)
+	}
+
+	if aJson.GetAsIntPath(paths[0]) != 5 || aJson.GetAsIntPath(BuildPath("2024", "x", 0)) != 7 {
+		t.Fatal(paths[0])
+	}
+
+	if aJson.GetAsIntPath(BuildPath("0")) != 1 || aJson.GetAsIntPath(BuildPath("")) != 2 || aJson.GetAsIntPath(`["list"][0]`) != 3 {
+		t.Fatal(aJson.ToString())
+	}
+
+	if err := aJson.UpdatePath(paths[0], 6); err != nil || aJson.GetAsIntPath(`["2024"]["01"]`) != 6 {
+		t.Fatal(err, aJson.ToString())
+	}
+
+	if err := aJson.RemovePath(BuildPath("0")); err != nil || aJson.HasKey("0") {
+		t.Fatal(err, aJson.ToString())
+	}
+}
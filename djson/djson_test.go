@@ -1,7 +1,9 @@
 package djson
 
 import (
+	"errors"
 	"log"
+	"strings"
 	"testing"
 )
 
@@ -320,3 +322,55 @@ func TestFloat(t *testing.T) {
 	log.Println("2")
 
 }
+
+func TestParseWithError(t *testing.T) {
+	jsonDoc := `{
+		"name": "Ricardo Longa",
+		"skills": ["Golang" "Android"]
+	}`
+
+	_, err := NewDJSON().ParseWithError(jsonDoc)
+
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+
+	if !errors.Is(err, InvalidSyntaxError) || perr.Line != 3 || perr.Column != 23 {
+		t.Fatal(perr)
+	}
+
+	if !strings.Contains(perr.Snippet, `"Golang" "And`) {
+		t.Fatal(perr.Snippet)
+	}
+
+	if _, err := NewDJSON().ParseWithError(`{"a": 1`); !errors.Is(err, UnexpectedEndError) {
+		t.Fatal(err)
+	}
+
+	if _, err := NewDJSON().ParseWithError(`[1] 2`); !errors.Is(err, TrailingDataError) {
+		t.Fatal(err)
+	}
+
+	if _, err := NewDJSON().ParseWithError(``); !errors.Is(err, UnexpectedEndError) {
+		t.Fatal(err)
+	}
+
+	nJson, err := NewDJSON().ParseWithError(` null `)
+	if err != nil || !nJson.IsNull() {
+		t.Fatal("null must parse without error")
+	}
+
+	if _, err := NewObject().ParseWithError(`  [1, 2]`); !errors.Is(err, NotObjectError) {
+		t.Fatal(err)
+	}
+
+	if _, err := NewArray().ParseWithError(`{"a": 1}`); !errors.Is(err, NotArrayError) {
+		t.Fatal(err)
+	}
+
+	obj, err := NewObject().ParseWithError(`{"a": [1, 2], "b": {"c": "d"}}`)
+	if err != nil || obj.GetAsString("b") != `{"c":"d"}` {
+		t.Fatal(err)
+	}
+}
@@ -471,6 +471,10 @@ func (m *DJSON) SortObjectArrayDesc(key string) bool {
 }
 
 func (m *DJSON) Equal(t *DJSON) bool {
+	if eq, ok := numberEqual(m.GetAsInterface(), t.GetAsInterface()); ok {
+		return eq
+	}
+
 	if m.JsonType != t.JsonType {
 		return false
 	}
@@ -497,6 +501,9 @@ func (m *DJSON) Equal(t *DJSON) bool {
 
 func (m *DJSON) Clone() *DJSON {
 	t := NewDJSON(m.JsonType)
+	t.ordered = m.ordered
+	t.lossless = m.lossless
+	t.number = m.number
 
 	switch m.JsonType {
 	case JSON_NULL:
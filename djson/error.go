@@ -1,7 +1,89 @@
 package djson
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 var invalidPathError = errors.New("invalid path")
 var unavailableError = errors.New("path func unavailable")
 var failedToSortError = errors.New("failedToSortError")
+
+var InvalidSyntaxError = errors.New("invalid syntax")
+var UnexpectedEndError = errors.New("unexpected end of input")
+var TrailingDataError = errors.New("trailing data after value")
+var NumberRangeError = errors.New("number out of range")
+var NotObjectError = errors.New("not object")
+var NotArrayError = errors.New("not array")
+
+var InvalidPointerError = errors.New("invalid JSON pointer")
+var PointerNotFoundError = errors.New("JSON pointer not found")
+var InvalidPatchError = errors.New("invalid JSON patch")
+var PatchTestFailedError = errors.New("JSON patch test failed")
+
+var InvalidQueryError = errors.New("invalid query")
+var InvalidFlatKeyError = errors.New("invalid flattened key")
+
+var UnsupportedTypeError = errors.New("unsupported type")
+var UnsupportedValueError = errors.New("unsupported value")
+var TypeMismatchError = errors.New("type mismatch")
+var InvalidUnmarshalError = errors.New("unmarshal target must be a non-nil pointer")
+var PathNotFoundError = errors.New("path not found")
+var NullValueError = errors.New("null value")
+
+var InvalidBinaryError = errors.New("invalid binary encoding")
+
+var NoSampleError = errors.New("no sample documents")
+var InvalidSchemaError = errors.New("invalid JSON schema")
+var InvalidTypeError = errors.New("invalid validator type")
+
+const parseErrorSnippetLen = 20
+
+type ParseError struct {
+	Code    error
+	Msg     string
+	Offset  int64
+	Line    int
+	Column  int
+	Snippet string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at line %d, column %d (offset %d): %q", e.Msg, e.Line, e.Column, e.Offset, e.Snippet)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Code
+}
+
+func newParseErrorAt(doc string, offset int64, code error, msg string) *ParseError {
+	if offset > int64(len(doc)) {
+		offset = int64(len(doc))
+	}
+
+	head := doc[:offset]
+	line := strings.Count(head, "\n") + 1
+	column := len([]rune(head[strings.LastIndex(head, "\n")+1:]))
+	if column == 0 {
+		column = 1
+	}
+
+	from := offset - parseErrorSnippetLen
+	if from < 0 {
+		from = 0
+	}
+	to := offset + parseErrorSnippetLen
+	if to > int64(len(doc)) {
+		to = int64(len(doc))
+	}
+
+	return &ParseError{
+		Code:    code,
+		Msg:     msg,
+		Offset:  offset,
+		Line:    line,
+		Column:  column,
+		Snippet: strings.ToValidUTF8(doc[from:to], ""),
+	}
+}
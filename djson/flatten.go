@@ -0,0 +1,319 @@
+package djson
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FlattenOptions sets how flattened keys are written. Separator goes
+// between object keys (default "."); IndexFormat renders array indexes and
+// must contain a single %d (default "[%d]"). With the defaults, keys look
+// like a.b[0].c. Keys that would be ambiguous are written quoted, as in
+// a["b.c"].
+
+type FlattenOptions struct {
+	Separator   string
+	IndexFormat string
+}
+
+type flattener struct {
+	sep         string
+	indexPrefix string
+	indexSuffix string
+}
+
+func newFlattener(opts []*FlattenOptions) (*flattener, error) {
+	f := &flattener{
+		sep:         ".",
+		indexPrefix: "[",
+		indexSuffix: "]",
+	}
+
+	if len(opts) == 0 || opts[0] == nil {
+		return f, nil
+	}
+
+	if opts[0].Separator != "" {
+		f.sep = opts[0].Separator
+	}
+
+	if opts[0].IndexFormat != "" {
+		parts := strings.Split(opts[0].IndexFormat, "%d")
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("index format %q: %w", opts[0].IndexFormat, InvalidFlatKeyError)
+		}
+		f.indexPrefix, f.indexSuffix = parts[0], parts[1]
+	}
+
+	return f, nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for idx := 0; idx < len(s); idx++ {
+		if s[idx] < '0' || s[idx] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *flattener) key(prefix string, k string) string {
+	if k == "" || isDigits(k) || strings.ContainsAny(k, `[]"'\`) ||
+		strings.Contains(k, f.sep) || strings.Contains(k, f.indexPrefix) {
+		if strings.Contains(k, `"`) {
+			return prefix + `['` + k + `']`
+		}
+		return prefix + `["` + k + `"]`
+	}
+
+	if prefix == "" {
+		return k
+	}
+	return prefix + f.sep + k
+}
+
+func (f *flattener) index(prefix string, idx int) string {
+	return prefix + f.indexPrefix + strconv.Itoa(idx) + f.indexSuffix
+}
+
+func (f *flattener) flatten(ret map[string]interface{}, keys *[]string, prefix string, element interface{}) {
+	switch t := element.(type) {
+	case *DO:
+		if t.Size() == 0 {
+			break
+		}
+		for _, k := range t.Keys() {
+			f.flatten(ret, keys, f.key(prefix, k), t.Map[k])
+		}
+		return
+	case *DA:
+		if t.Size() == 0 {
+			break
+		}
+		for idx := range t.Element {
+			f.flatten(ret, keys, f.index(prefix, idx), t.Element[idx])
+		}
+		return
+	}
+
+	switch t := element.(type) {
+	case *DO:
+		ret[prefix] = map[string]interface{}{}
+	case *DA:
+		ret[prefix] = []interface{}{}
+	default:
+		ret[prefix] = t
+	}
+	*keys = append(*keys, prefix)
+}
+
+// Flatten returns the leaves of m keyed by their path. Empty objects and
+// arrays are kept as leaves so that Unflatten restores them.
+
+func (m *DJSON) Flatten(opts ...*FlattenOptions) (map[string]interface{}, error) {
+	ret, _, err := m.flatten(opts)
+	return ret, err
+}
+
+// FlattenKeys returns the keys of Flatten in document order.
+
+func (m *DJSON) FlattenKeys(opts ...*FlattenOptions) ([]string, error) {
+	_, keys, err := m.flatten(opts)
+	return keys, err
+}
+
+func (m *DJSON) flatten(opts []*FlattenOptions) (map[string]interface{}, []string, error) {
+	f, err := newFlattener(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ret := make(map[string]interface{})
+	keys := make([]string, 0)
+	f.flatten(ret, &keys, "", m.GetAsInterface())
+
+	return ret, keys, nil
+}
+
+// tokens parses a flattened key back into keys and indexes.
+
+func (f *flattener) tokens(key string) ([]interface{}, error) {
+	tokens := make([]interface{}, 0)
+	invalid := fmt.Errorf("%q: %w", key, InvalidFlatKeyError)
+
+	for pos := 0; pos < len(key); {
+		rest := key[pos:]
+
+		switch {
+		case strings.HasPrefix(rest, `["`), strings.HasPrefix(rest, `['`):
+			end := strings.Index(rest[2:], rest[1:2]+"]")
+			if end < 0 {
+				return nil, invalid
+			}
+			tokens = append(tokens, rest[2:2+end])
+			pos += end + 4
+			continue
+		case strings.HasPrefix(rest, f.indexPrefix):
+			digits := rest[len(f.indexPrefix):]
+			n := 0
+			for n < len(digits) && digits[n] >= '0' && digits[n] <= '9' {
+				n++
+			}
+			after := digits[n:]
+			if n > 0 && strings.HasPrefix(after, f.indexSuffix) {
+				after = after[len(f.indexSuffix):]
+			} else {
+				n = 0
+			}
+			if n > 0 && (after == "" || strings.HasPrefix(after, f.sep) || strings.HasPrefix(after, f.indexPrefix) || strings.HasPrefix(after, "[")) {
+				idx, err := strconv.Atoi(digits[:n])
+				if err != nil {
+					return nil, invalid
+				}
+				tokens = append(tokens, idx)
+				pos += len(f.indexPrefix) + n + len(f.indexSuffix)
+				continue
+			}
+		}
+
+		if len(tokens) > 0 {
+			if !strings.HasPrefix(rest, f.sep) {
+				return nil, invalid
+			}
+			rest = rest[len(f.sep):]
+			pos += len(f.sep)
+		}
+
+		end := len(rest)
+		for _, stop := range []string{f.sep, f.indexPrefix, `["`, `['`} {
+			if idx := strings.Index(rest, stop); idx >= 0 && idx < end {
+				end = idx
+			}
+		}
+		if end == 0 {
+			return nil, invalid
+		}
+
+		if isDigits(rest[:end]) {
+			idx, err := strconv.Atoi(rest[:end])
+			if err != nil {
+				return nil, invalid
+			}
+			tokens = append(tokens, idx)
+		} else {
+			tokens = append(tokens, rest[:end])
+		}
+		pos += end
+	}
+
+	return tokens, nil
+}
+
+// unflattenMaxGap is the number of array elements not named by any key
+// that Unflatten adds in all, so that a key such as a[999999999] does not
+// allocate a billion elements.
+
+const unflattenMaxGap = 65536
+
+// Unflatten rebuilds the document of a flattened map. Array elements not
+// named by any key are null, up to 65536 of them in all. Keys that disagree
+// on the shape of the tree, or name an index past that, are reported as
+// InvalidFlatKeyError.
+
+func Unflatten(flat map[string]interface{}, opts ...*FlattenOptions) (*DJSON, error) {
+	f, err := newFlattener(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(flat))
+	for k := range flat {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var root interface{}
+	budget := len(flat) + unflattenMaxGap
+
+	for _, k := range keys {
+		tokens, err := f.tokens(k)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(tokens) == 0 {
+			if len(flat) > 1 {
+				return nil, fmt.Errorf("%q: %w", k, InvalidFlatKeyError)
+			}
+			root = flat[k]
+			break
+		}
+
+		if root, err = unflattenSet(root, tokens, flat[k], &budget); err != nil {
+			return nil, fmt.Errorf("%q: %w", k, err)
+		}
+	}
+
+	holder := NewObject().Put("", root)
+	ret, _ := elementToDJSON(holder.Map[""])
+	return ret, nil
+}
+
+// unflattenSet takes the elements it adds to arrays from budget.
+
+func unflattenSet(parent interface{}, tokens []interface{}, value interface{}, budget *int) (interface{}, error) {
+	if len(tokens) == 0 {
+		if parent != nil {
+			return nil, InvalidFlatKeyError
+		}
+		return value, nil
+	}
+
+	switch t := tokens[0].(type) {
+	case string:
+		if parent == nil {
+			parent = NewObject()
+		}
+		obj, ok := parent.(*DO)
+		if !ok {
+			return nil, InvalidFlatKeyError
+		}
+
+		child, err := unflattenSet(obj.Map[t], tokens[1:], value, budget)
+		if err != nil {
+			return nil, err
+		}
+		obj.Put(t, child)
+	case int:
+		if parent == nil {
+			parent = NewArray()
+		}
+		arr, ok := parent.(*DA)
+		if !ok {
+			return nil, InvalidFlatKeyError
+		}
+
+		if t >= arr.Size()+*budget {
+			return nil, fmt.Errorf("index %d too large: %w", t, InvalidFlatKeyError)
+		}
+
+		if grow := t + 1 - arr.Size(); grow > 0 {
+			*budget -= grow
+			arr.Element = append(arr.Element, make([]interface{}, grow)...)
+		}
+
+		child, err := unflattenSet(arr.Element[t], tokens[1:], value, budget)
+		if err != nil {
+			return nil, err
+		}
+		arr.Element[t] = nil
+		arr.ReplaceAt(t, child)
+	}
+
+	return parent, nil
+}
@@ -0,0 +1,92 @@
+package djson
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	aJson := NewDJSON().Parse(`{"a":{"b":[{"c":1},{"c":2,"d":null}]},"e":"x","f":[],"g":{},"h.i":true,"0":1.5}`)
+
+	flat, err := aJson.Flatten()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, _ := aJson.FlattenKeys()
+	expected := []string{`["0"]`, "a.b[0].c", "a.b[1].c", "a.b[1].d", "e", "f", "g", `["h.i"]`}
+	if len(keys) != len(expected) || len(flat) != len(expected) {
+		t.Fatal(keys)
+	}
+	for idx := range expected {
+		if keys[idx] != expected[idx] {
+			t.Fatal(keys)
+		}
+	}
+
+	if flat["a.b[1].c"] != int64(2) || aJson.GetAsIntPath(`["a"]["b"][1]["c"]`) != 2 || !aJson.GetAsBoolPath(`["h.i"]`) {
+		t.Fatal(flat)
+	}
+
+	bJson, err := Unflatten(flat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bJson.Equal(aJson) {
+		t.Fatalf("expected %s, got %s", aJson.ToString(), bJson.ToString())
+	}
+}
+
+func TestFlattenOptions(t *testing.T) {
+	aJson := NewDJSON().Parse(`{"a":{"b":[{"c":1},{"c":2}]}}`)
+	opts := &FlattenOptions{Separator: "/", IndexFormat: "/%d"}
+
+	keys, err := aJson.FlattenKeys(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(keys) != 2 || keys[0] != "a/b/0/c" || keys[1] != "a/b/1/c" {
+		t.Fatal(keys)
+	}
+
+	flat, _ := aJson.Flatten(opts)
+	bJson, err := Unflatten(flat, opts)
+	if err != nil || !bJson.Equal(aJson) {
+		t.Fatal(err, bJson.ToString())
+	}
+
+	if _, err := Unflatten(map[string]interface{}{"a": 1, "a.b": 2}); !errors.Is(err, InvalidFlatKeyError) {
+		t.Fatal(err)
+	}
+
+	if _, err := aJson.Flatten(&FlattenOptions{IndexFormat: "%d"}); !errors.Is(err, InvalidFlatKeyError) {
+		t.Fatal(err)
+	}
+
+	cJson, err := Unflatten(map[string]interface{}{"a[2]": "z"})
+	if err != nil || cJson.ToString() != `{"a":[null,null,"z"]}` {
+		t.Fatal(err, cJson.ToString())
+	}
+
+	for _, k := range []string{"a[999999999]", "a[9223372036854775807]", "a[0][9223372036854775807]"} {
+		if _, err := Unflatten(map[string]interface{}{k: 1}); !errors.Is(err, InvalidFlatKeyError) {
+			t.Fatal(k, err)
+		}
+	}
+
+	if _, err := Unflatten(map[string]interface{}{"a[40000]": 1, "b[40000]": 1}); !errors.Is(err, InvalidFlatKeyError) {
+		t.Fatal(err)
+	}
+
+	big := NewArray()
+	for idx := 0; idx < unflattenMaxGap+10; idx++ {
+		big.PushBack(idx)
+	}
+	dJson := NewDJSON().Put("a", big)
+	flat, _ = dJson.Flatten()
+	if eJson, err := Unflatten(flat); err != nil || !eJson.Equal(dJson) {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,924 @@
+package djson
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var schemaUUIDRegExp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// SchemaFormats holds the checks of the JSON Schema "format" keyword. The
+// standard names are lower case; the upper case names are the string types
+// of the Validator syntax, so that "format": "HEX" or "format": "TELEPHONE"
+// work as they do there, as do string types added with RegisterType.
+// Formats that are not known are ignored, as the specification allows.
+//
+// SchemaFormats is read without locking while schemas compile, so it must
+// not be changed while any schema may be compiling; add entries at
+// initialization, or pass them per schema with SchemaOptions.
+
+var SchemaFormats = map[string]func(string, ...int64) bool{
+	"email":     CheckFuncEmail,
+	"uuid":      checkFormatUUID,
+	"date":      checkFormatDate,
+	"date-time": checkFormatDateTime,
+	"ipv4":      checkFormatIPv4,
+	"ipv6":      checkFormatIPv6,
+	"uri":       checkFormatURI,
+
+	"BIN":          CheckFuncBin,
+	"DEC":          CheckFuncDec,
+	"HEX":          CheckFuncHex,
+	"TIMESTAMP":    CheckFuncTimestamp,
+	"YYYYMMDD":     CheckFuncYYYYMMDD,
+	"YYMMDD":       CheckFuncYYMMDD,
+	"HHMMSS":       CheckFuncHHMMSS,
+	"HHMM":         CheckFuncHHMM,
+	"EMAIL":        CheckFuncEmail,
+	"UUID":         CheckFuncUUID,
+	"INT.STRING":   CheckFuncIntString,
+	"FLOAT.STRING": CheckFuncFloatString,
+	"BOOL.STRING":  CheckFuncBoolString,
+	"ISO31661A2":   CheckISO31661A2,
+	"ISO31662":     CheckISO31662,
+	"BASE64":       CheckBase64,
+	"TELEPHONE":    CheckTelephone,
+}
+
+func checkFormatUUID(ts string, vi ...int64) bool {
+	return schemaUUIDRegExp.MatchString(ts)
+}
+
+func checkFormatDate(ts string, vi ...int64) bool {
+	_, err := time.Parse("2006-01-02", ts)
+	return err == nil
+}
+
+func checkFormatDateTime(ts string, vi ...int64) bool {
+	_, err := time.Parse(time.RFC3339Nano, strings.ToUpper(ts))
+	return err == nil
+}
+
+func checkFormatIPv4(ts string, vi ...int64) bool {
+	ip := net.ParseIP(ts)
+	return ip != nil && ip.To4() != nil && !strings.Contains(ts, ":")
+}
+
+func checkFormatIPv6(ts string, vi ...int64) bool {
+	return net.ParseIP(ts) != nil && strings.Contains(ts, ":")
+}
+
+func checkFormatURI(ts string, vi ...int64) bool {
+	u, err := url.Parse(ts)
+	return err == nil && u.IsAbs()
+}
+
+// SchemaOptions adds formats to SchemaFormats, or overrides them, for one
+// schema.
+
+type SchemaOptions struct {
+	Formats map[string]func(string, ...int64) bool
+}
+
+// Schema is a compiled JSON Schema (draft 2020-12). The supported keywords
+// are type, enum, const, minimum, maximum, exclusiveMinimum,
+// exclusiveMaximum, multipleOf, minLength, maxLength, pattern, format,
+// items, prefixItems, minItems, maxItems, uniqueItems, properties,
+// additionalProperties, required, minProperties, maxProperties, allOf,
+// anyOf, oneOf, not, $ref and $defs; others are ignored. $ref must point
+// into the same document, e.g. "#/$defs/user" or "#".
+
+type Schema struct {
+	root *schemaRule
+}
+
+type schemaRule struct {
+	pointer string
+
+	isBool    bool
+	boolValue bool
+
+	types  []string
+	values vvalues
+
+	minimum          interface{}
+	maximum          interface{}
+	exclusiveMinimum interface{}
+	exclusiveMaximum interface{}
+	multipleOf       interface{}
+
+	minLength  int64
+	maxLength  int64
+	pattern    *regexp.Regexp
+	format     string
+	formatFunc func(string, ...int64) bool
+
+	prefixItems []*schemaRule
+	items       *schemaRule
+	minItems    int64
+	maxItems    int64
+	uniqueItems bool
+
+	properties    map[string]*schemaRule
+	additional    *schemaRule
+	required      []string
+	minProperties int64
+	maxProperties int64
+
+	allOf []*schemaRule
+	anyOf []*schemaRule
+	oneOf []*schemaRule
+	not   *schemaRule
+	ref   *schemaRule
+}
+
+type schemaCompiler struct {
+	root    interface{}
+	formats map[string]func(string, ...int64) bool
+	rules   map[string]*schemaRule
+}
+
+// CompileSchema compiles a JSON Schema document. Malformed keywords,
+// unresolvable $ref and $ref loops that do not go down into the value, such
+// as {"$ref": "#"}, are reported with InvalidSchemaError.
+
+func CompileSchema(schema *DJSON, opts ...*SchemaOptions) (*Schema, error) {
+	c := &schemaCompiler{
+		root:    schema.GetAsInterface(),
+		formats: SchemaFormats,
+		rules:   make(map[string]*schemaRule),
+	}
+
+	if len(opts) > 0 && opts[0] != nil && len(opts[0].Formats) > 0 {
+		c.formats = make(map[string]func(string, ...int64) bool, len(SchemaFormats)+len(opts[0].Formats))
+		for k, v := range SchemaFormats {
+			c.formats[k] = v
+		}
+		for k, v := range opts[0].Formats {
+			c.formats[k] = v
+		}
+	}
+
+	root, err := c.compile(c.root, []interface{}{})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.checkCycles(); err != nil {
+		return nil, err
+	}
+
+	return &Schema{root: root}, nil
+}
+
+func schemaInvalid(path []interface{}, msg string) error {
+	return fmt.Errorf("%s: %s: %w", BuildPointer(path...), msg, InvalidSchemaError)
+}
+
+func (c *schemaCompiler) compile(element interface{}, path []interface{}) (*schemaRule, error) {
+	pointer := BuildPointer(path...)
+	if rule, ok := c.rules[pointer]; ok {
+		return rule, nil
+	}
+
+	rule := &schemaRule{
+		pointer:       pointer,
+		minLength:     -1,
+		maxLength:     -1,
+		minItems:      -1,
+		maxItems:      -1,
+		minProperties: -1,
+		maxProperties: -1,
+	}
+	c.rules[pointer] = rule
+
+	if b, ok := element.(bool); ok {
+		rule.isBool = true
+		rule.boolValue = b
+		return rule, nil
+	}
+
+	obj, ok := element.(*DO)
+	if !ok {
+		return nil, schemaInvalid(path, "schema must be an object or a boolean")
+	}
+
+	var err error
+
+	if v, ok := obj.Map["$defs"]; ok {
+		defs, ok := v.(*DO)
+		if !ok {
+			return nil, schemaInvalid(appendPath(path, "$defs"), "must be an object")
+		}
+		for _, k := range defs.Keys() {
+			if _, err = c.compile(defs.Map[k], appendPath(appendPath(path, "$defs"), k)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if v, ok := obj.Map["$ref"]; ok {
+		if rule.ref, err = c.resolve(v, appendPath(path, "$ref")); err != nil {
+			return nil, err
+		}
+	}
+
+	if v, ok := obj.Map["type"]; ok {
+		if rule.types, err = schemaTypes(v, appendPath(path, "type")); err != nil {
+			return nil, err
+		}
+	}
+
+	if v, ok := obj.Map["enum"]; ok {
+		arr, ok := v.(*DA)
+		if !ok {
+			return nil, schemaInvalid(appendPath(path, "enum"), "must be an array")
+		}
+		rule.values.enumRaw = arr
+		rule.values.enum = make([]string, 0, arr.Size())
+		for idx := range arr.Element {
+			rule.values.enum = append(rule.values.enum, canonicalKey(arr.Element[idx]))
+		}
+	}
+
+	if v, ok := obj.Map["const"]; ok {
+		rule.values.hasConst = true
+		rule.values.constRaw = v
+		rule.values.constKey = canonicalKey(v)
+	}
+
+	for _, kw := range []struct {
+		name   string
+		target *interface{}
+	}{
+		{"minimum", &rule.minimum},
+		{"maximum", &rule.maximum},
+		{"exclusiveMinimum", &rule.exclusiveMinimum},
+		{"exclusiveMaximum", &rule.exclusiveMaximum},
+		{"multipleOf", &rule.multipleOf},
+	} {
+		v, ok := obj.Map[kw.name]
+		if !ok {
+			continue
+		}
+		if !isNumberElement(v) {
+			return nil, schemaInvalid(appendPath(path, kw.name), "must be a number")
+		}
+		if kw.name == "multipleOf" {
+			if f, _ := getFloatBase(v); f <= 0 {
+				return nil, schemaInvalid(appendPath(path, kw.name), "must be greater than 0")
+			}
+		}
+		*kw.target = v
+	}
+
+	for _, kw := range []struct {
+		name   string
+		target *int64
+	}{
+		{"minLength", &rule.minLength},
+		{"maxLength", &rule.maxLength},
+		{"minItems", &rule.minItems},
+		{"maxItems", &rule.maxItems},
+		{"minProperties", &rule.minProperties},
+		{"maxProperties", &rule.maxProperties},
+	} {
+		v, ok := obj.Map[kw.name]
+		if !ok {
+			continue
+		}
+		n, ok := schemaCount(v)
+		if !ok {
+			return nil, schemaInvalid(appendPath(path, kw.name), "must be a non-negative integer")
+		}
+		*kw.target = n
+	}
+
+	if v, ok := obj.Map["pattern"]; ok {
+		s, ok := v.(string)
+		if !ok {
+			return nil, schemaInvalid(appendPath(path, "pattern"), "must be a string")
+		}
+		if rule.pattern, err = regexp.Compile(s); err != nil {
+			return nil, schemaInvalid(appendPath(path, "pattern"), err.Error())
+		}
+	}
+
+	if v, ok := obj.Map["format"]; ok {
+		s, ok := v.(string)
+		if !ok {
+			return nil, schemaInvalid(appendPath(path, "format"), "must be a string")
+		}
+		rule.format = s
+		rule.formatFunc = c.formats[s]
+		if vt, ok := lookupType(s); ok && rule.formatFunc == nil && vt.Type == V_TYPE_STRING {
+			rule.formatFunc = vt.CheckFunc
+		}
+	}
+
+	if v, ok := obj.Map["prefixItems"]; ok {
+		if rule.prefixItems, err = c.compileList(v, appendPath(path, "prefixItems")); err != nil {
+			return nil, err
+		}
+	}
+
+	if v, ok := obj.Map["items"]; ok {
+		if rule.items, err = c.compile(v, appendPath(path, "items")); err != nil {
+			return nil, err
+		}
+	}
+
+	if v, ok := obj.Map["uniqueItems"]; ok {
+		if rule.uniqueItems, ok = v.(bool); !ok {
+			return nil, schemaInvalid(appendPath(path, "uniqueItems"), "must be a boolean")
+		}
+	}
+
+	if v, ok := obj.Map["properties"]; ok {
+		props, ok := v.(*DO)
+		if !ok {
+			return nil, schemaInvalid(appendPath(path, "properties"), "must be an object")
+		}
+		rule.properties = make(map[string]*schemaRule, props.Size())
+		for _, k := range props.Keys() {
+			if rule.properties[k], err = c.compile(props.Map[k], appendPath(appendPath(path, "properties"), k)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if v, ok := obj.Map["additionalProperties"]; ok {
+		if rule.additional, err = c.compile(v, appendPath(path, "additionalProperties")); err != nil {
+			return nil, err
+		}
+	}
+
+	if v, ok := obj.Map["required"]; ok {
+		arr, ok := v.(*DA)
+		if !ok {
+			return nil, schemaInvalid(appendPath(path, "required"), "must be an array of strings")
+		}
+		for idx := range arr.Element {
+			s, ok := arr.Element[idx].(string)
+			if !ok {
+				return nil, schemaInvalid(appendPath(path, "required"), "must be an array of strings")
+			}
+			rule.required = append(rule.required, s)
+		}
+	}
+
+	for _, kw := range []struct {
+		name   string
+		target *[]*schemaRule
+	}{
+		{"allOf", &rule.allOf},
+		{"anyOf", &rule.anyOf},
+		{"oneOf", &rule.oneOf},
+	} {
+		v, ok := obj.Map[kw.name]
+		if !ok {
+			continue
+		}
+		if *kw.target, err = c.compileList(v, appendPath(path, kw.name)); err != nil {
+			return nil, err
+		}
+		if len(*kw.target) == 0 {
+			return nil, schemaInvalid(appendPath(path, kw.name), "must not be empty")
+		}
+	}
+
+	if v, ok := obj.Map["not"]; ok {
+		if rule.not, err = c.compile(v, appendPath(path, "not")); err != nil {
+			return nil, err
+		}
+	}
+
+	return rule, nil
+}
+
+func (c *schemaCompiler) compileList(element interface{}, path []interface{}) ([]*schemaRule, error) {
+	arr, ok := element.(*DA)
+	if !ok {
+		return nil, schemaInvalid(path, "must be an array")
+	}
+
+	rules := make([]*schemaRule, 0, arr.Size())
+	for idx := range arr.Element {
+		rule, err := c.compile(arr.Element[idx], appendPath(path, idx))
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// checkCycles rejects $ref loops that come back to a rule without going
+// down into a property or an item, which would recurse on the same value
+// forever.
+
+func (c *schemaCompiler) checkCycles() error {
+	const (
+		visiting = 1
+		done     = 2
+	)
+
+	state := make(map[*schemaRule]int, len(c.rules))
+
+	var visit func(rule *schemaRule) error
+	visit = func(rule *schemaRule) error {
+		state[rule] = visiting
+
+		next := make([]*schemaRule, 0, len(rule.allOf)+len(rule.anyOf)+len(rule.oneOf)+2)
+		next = append(next, rule.allOf...)
+		next = append(next, rule.anyOf...)
+		next = append(next, rule.oneOf...)
+		if rule.not != nil {
+			next = append(next, rule.not)
+		}
+		if rule.ref != nil {
+			next = append(next, rule.ref)
+		}
+
+		for _, sub := range next {
+			switch state[sub] {
+			case visiting:
+				return fmt.Errorf("%s: reference cycle through %q: %w", rule.pointer, "#"+sub.pointer, InvalidSchemaError)
+			case done:
+				continue
+			}
+			if err := visit(sub); err != nil {
+				return err
+			}
+		}
+
+		state[rule] = done
+		return nil
+	}
+
+	pointers := make([]string, 0, len(c.rules))
+	for pointer := range c.rules {
+		pointers = append(pointers, pointer)
+	}
+	sort.Strings(pointers)
+
+	for _, pointer := range pointers {
+		if rule := c.rules[pointer]; state[rule] == 0 {
+			if err := visit(rule); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// resolve compiles the target of a $ref, a JSON pointer in a URI fragment.
+
+func (c *schemaCompiler) resolve(element interface{}, path []interface{}) (*schemaRule, error) {
+	ref, ok := element.(string)
+	if !ok {
+		return nil, schemaInvalid(path, "must be a string")
+	}
+
+	if !strings.HasPrefix(ref, "#") {
+		return nil, schemaInvalid(path, fmt.Sprintf("unsupported reference %q", ref))
+	}
+
+	fragment, err := url.PathUnescape(ref[1:])
+	if err != nil {
+		return nil, schemaInvalid(path, fmt.Sprintf("invalid reference %q", ref))
+	}
+
+	tokens, err := ParsePointer(fragment)
+	if err != nil {
+		return nil, schemaInvalid(path, fmt.Sprintf("invalid reference %q", ref))
+	}
+
+	cur := c.root
+	target := make([]interface{}, 0, len(tokens))
+
+	for _, token := range tokens {
+		switch t := cur.(type) {
+		case *DO:
+			if cur, ok = t.Map[token]; !ok {
+				return nil, schemaInvalid(path, fmt.Sprintf("reference %q not found", ref))
+			}
+			target = append(target, token)
+		case *DA:
+			idx, err := strconv.Atoi(token)
+			if err != nil || idx < 0 || idx >= t.Size() {
+				return nil, schemaInvalid(path, fmt.Sprintf("reference %q not found", ref))
+			}
+			cur = t.Element[idx]
+			target = append(target, idx)
+		default:
+			return nil, schemaInvalid(path, fmt.Sprintf("reference %q not found", ref))
+		}
+	}
+
+	return c.compile(cur, target)
+}
+
+func schemaTypes(element interface{}, path []interface{}) ([]string, error) {
+	names := make([]string, 0)
+
+	switch t := element.(type) {
+	case string:
+		names = append(names, t)
+	case *DA:
+		for idx := range t.Element {
+			s, ok := t.Element[idx].(string)
+			if !ok {
+				return nil, schemaInvalid(path, "must be a string or an array of strings")
+			}
+			names = append(names, s)
+		}
+	default:
+		return nil, schemaInvalid(path, "must be a string or an array of strings")
+	}
+
+	for _, name := range names {
+		switch name {
+		case "null", "boolean", "object", "array", "number", "integer", "string":
+		default:
+			return nil, schemaInvalid(path, fmt.Sprintf("unknown type %q", name))
+		}
+	}
+
+	return names, nil
+}
+
+// schemaCount returns the non-negative integer of a count keyword. Counts
+// beyond math.MaxInt64 are rejected rather than wrapped.
+
+func schemaCount(element interface{}) (int64, bool) {
+	if !isNumberElement(element) {
+		return 0, false
+	}
+
+	switch t := element.(type) {
+	case int64:
+		return t, t >= 0
+	case json.Number:
+		if n, err := t.Int64(); err == nil {
+			return n, n >= 0
+		}
+	}
+
+	f, _ := getFloatBase(element)
+	if f < 0 || f != math.Trunc(f) || f >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// schemaType returns the JSON Schema type of element. Floats with no
+// fraction are integers, as the specification says.
+
+func schemaType(element interface{}) string {
+	switch elementType(element) {
+	case JSON_BOOL:
+		return "boolean"
+	case JSON_STRING:
+		return "string"
+	case JSON_INT:
+		return "integer"
+	case JSON_FLOAT:
+		if f, _ := getFloatBase(element); f == math.Trunc(f) && !math.IsInf(f, 0) {
+			return "integer"
+		}
+		return "number"
+	case JSON_OBJECT:
+		return "object"
+	case JSON_ARRAY:
+		return "array"
+	}
+	return "null"
+}
+
+// IsValid reports whether m is valid for the schema. A nil m is null.
+
+func (s *Schema) IsValid(m *DJSON) bool {
+	return s.root.validate(schemaInstance(m), []interface{}{}, nil)
+}
+
+// Validate returns every violation of the schema in m, with Rule set to
+// the failing keyword, or nil if m is valid.
+
+func (s *Schema) Validate(m *DJSON) []*ValidationError {
+	r := &vreport{}
+	s.root.validate(schemaInstance(m), []interface{}{}, r)
+	return r.errors
+}
+
+func schemaInstance(m *DJSON) interface{} {
+	if m == nil {
+		return nil
+	}
+	return m.GetAsInterface()
+}
+
+func (s *schemaRule) validate(v interface{}, path []interface{}, r *vreport) bool {
+	if s.isBool {
+		if s.boolValue {
+			return true
+		}
+		return r.fail(path, "false", V_ERR_FALSE, false, schemaType(v))
+	}
+
+	valid := true
+
+	for _, check := range []func(interface{}, []interface{}, *vreport) bool{
+		s.checkRef,
+		s.checkType,
+		s.checkEnum,
+		s.checkNumber,
+		s.checkString,
+		s.checkArray,
+		s.checkObject,
+		s.checkCombinators,
+	} {
+		if !check(v, path, r) {
+			if r == nil {
+				return false
+			}
+			valid = false
+		}
+	}
+
+	return valid
+}
+
+func (s *schemaRule) checkRef(v interface{}, path []interface{}, r *vreport) bool {
+	if s.ref == nil {
+		return true
+	}
+	return s.ref.validate(v, path, r)
+}
+
+func (s *schemaRule) checkType(v interface{}, path []interface{}, r *vreport) bool {
+	if len(s.types) == 0 {
+		return true
+	}
+
+	vtype := schemaType(v)
+	for _, t := range s.types {
+		if t == vtype || (t == "number" && vtype == "integer") {
+			return true
+		}
+	}
+
+	if len(s.types) == 1 {
+		return r.fail(path, "type", V_ERR_TYPE, s.types[0], vtype)
+	}
+	return r.fail(path, "type", V_ERR_TYPE, s.types, vtype)
+}
+
+func (s *schemaRule) checkEnum(v interface{}, path []interface{}, r *vreport) bool {
+	return s.values.checkValue(v, path, r)
+}
+
+func (s *schemaRule) checkNumber(v interface{}, path []interface{}, r *vreport) bool {
+	if !isNumberElement(v) {
+		return true
+	}
+
+	if s.minimum != nil && compareElements(v, s.minimum) < 0 {
+		return r.fail(path, "minimum", V_ERR_TOO_SMALL, s.minimum, v)
+	}
+	if s.exclusiveMinimum != nil && compareElements(v, s.exclusiveMinimum) <= 0 {
+		return r.fail(path, "exclusiveMinimum", V_ERR_TOO_SMALL, s.exclusiveMinimum, v)
+	}
+	if s.maximum != nil && compareElements(v, s.maximum) > 0 {
+		return r.fail(path, "maximum", V_ERR_TOO_LARGE, s.maximum, v)
+	}
+	if s.exclusiveMaximum != nil && compareElements(v, s.exclusiveMaximum) >= 0 {
+		return r.fail(path, "exclusiveMaximum", V_ERR_TOO_LARGE, s.exclusiveMaximum, v)
+	}
+
+	if s.multipleOf != nil && !isMultipleOf(v, s.multipleOf) {
+		return r.fail(path, "multipleOf", V_ERR_MULTIPLE_OF, s.multipleOf, v)
+	}
+
+	return true
+}
+
+func isMultipleOf(v, divisor interface{}) bool {
+	vr, vok := numberRat(v)
+	dr, dok := numberRat(divisor)
+	if vok && dok && dr.Sign() != 0 {
+		return vr.Quo(vr, dr).IsInt()
+	}
+
+	vf, _ := getFloatBase(v)
+	df, _ := getFloatBase(divisor)
+	q := vf / df
+	return !math.IsInf(q, 0) && q == math.Trunc(q)
+}
+
+func (s *schemaRule) checkString(v interface{}, path []interface{}, r *vreport) bool {
+	str, ok := v.(string)
+	if !ok {
+		return true
+	}
+
+	length := int64(utf8.RuneCountInString(str))
+
+	if s.minLength >= 0 && length < s.minLength {
+		return r.fail(path, "minLength", V_ERR_TOO_SHORT, s.minLength, length)
+	}
+	if s.maxLength >= 0 && length > s.maxLength {
+		return r.fail(path, "maxLength", V_ERR_TOO_LONG, s.maxLength, length)
+	}
+
+	if s.pattern != nil && !s.pattern.MatchString(str) {
+		return r.fail(path, "pattern", V_ERR_PATTERN, s.pattern.String(), str)
+	}
+
+	if s.formatFunc != nil && !s.formatFunc(str) {
+		return r.fail(path, "format", V_ERR_FORMAT, s.format, str)
+	}
+
+	return true
+}
+
+func (s *schemaRule) checkArray(v interface{}, path []interface{}, r *vreport) bool {
+	arr, ok := v.(*DA)
+	if !ok {
+		return true
+	}
+
+	size := int64(arr.Size())
+
+	if s.minItems >= 0 && size < s.minItems {
+		return r.fail(path, "minItems", V_ERR_TOO_FEW, s.minItems, size)
+	}
+	if s.maxItems >= 0 && size > s.maxItems {
+		return r.fail(path, "maxItems", V_ERR_TOO_MANY, s.maxItems, size)
+	}
+
+	valid := true
+
+	if s.uniqueItems {
+		seen := make(map[string]int, arr.Size())
+		for idx := range arr.Element {
+			key := canonicalKey(arr.Element[idx])
+			if first, ok := seen[key]; ok {
+				if valid = r.fail(appendPath(path, idx), "uniqueItems", V_ERR_NOT_UNIQUE, BuildPointer(appendPath(path, first)...), arr.Element[idx]); r == nil {
+					return false
+				}
+				continue
+			}
+			seen[key] = idx
+		}
+	}
+
+	for idx := range arr.Element {
+		item := s.items
+		if idx < len(s.prefixItems) {
+			item = s.prefixItems[idx]
+		}
+		if item == nil {
+			continue
+		}
+
+		if !item.validate(arr.Element[idx], appendPath(path, idx), r) {
+			if r == nil {
+				return false
+			}
+			valid = false
+		}
+	}
+
+	return valid
+}
+
+func (s *schemaRule) checkObject(v interface{}, path []interface{}, r *vreport) bool {
+	obj, ok := v.(*DO)
+	if !ok {
+		return true
+	}
+
+	size := int64(obj.Size())
+
+	if s.minProperties >= 0 && size < s.minProperties {
+		return r.fail(path, "minProperties", V_ERR_TOO_FEW_PROPS, s.minProperties, size)
+	}
+	if s.maxProperties >= 0 && size > s.maxProperties {
+		return r.fail(path, "maxProperties", V_ERR_TOO_MANY_PROPS, s.maxProperties, size)
+	}
+
+	valid := true
+
+	for _, k := range s.required {
+		if _, ok := obj.Map[k]; !ok {
+			if valid = r.fail(appendPath(path, k), "required", V_ERR_REQUIRED, true, nil); r == nil {
+				return false
+			}
+		}
+	}
+
+	for _, k := range obj.Keys() {
+		prop, ok := s.properties[k]
+		if !ok {
+			prop = s.additional
+		}
+		if prop == nil {
+			continue
+		}
+
+		if !ok && prop.isBool && !prop.boolValue {
+			if valid = r.fail(appendPath(path, k), "additionalProperties", V_ERR_ADDITIONAL, false, nil); r == nil {
+				return false
+			}
+			continue
+		}
+
+		if !prop.validate(obj.Map[k], appendPath(path, k), r) {
+			if r == nil {
+				return false
+			}
+			valid = false
+		}
+	}
+
+	return valid
+}
+
+func (s *schemaRule) checkCombinators(v interface{}, path []interface{}, r *vreport) bool {
+	valid := true
+
+	for _, sub := range s.allOf {
+		if !sub.validate(v, path, r) {
+			if r == nil {
+				return false
+			}
+			valid = false
+		}
+	}
+
+	if len(s.anyOf) > 0 {
+		matched := false
+		for _, sub := range s.anyOf {
+			if sub.validate(v, path, nil) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			if valid = s.failAlternatives(s.anyOf, "anyOf", v, path, r); r == nil {
+				return false
+			}
+		}
+	}
+
+	if len(s.oneOf) > 0 {
+		matched := 0
+		for _, sub := range s.oneOf {
+			if sub.validate(v, path, nil) {
+				matched++
+			}
+		}
+		switch {
+		case matched == 0:
+			valid = s.failAlternatives(s.oneOf, "oneOf", v, path, r)
+		case matched > 1:
+			valid = r.fail(path, "oneOf", V_ERR_MULTI_MATCH, 1, matched)
+		}
+		if !valid && r == nil {
+			return false
+		}
+	}
+
+	if s.not != nil && s.not.validate(v, path, nil) {
+		valid = r.fail(path, "not", V_ERR_NOT, s.not.pointer, v)
+	}
+
+	return valid
+}
+
+// failAlternatives reports the violations of a single alternative, or
+// V_ERR_NO_MATCH listing the pointers of the alternatives.
+
+func (s *schemaRule) failAlternatives(subs []*schemaRule, keyword string, v interface{}, path []interface{}, r *vreport) bool {
+	if len(subs) == 1 {
+		return subs[0].validate(v, path, r)
+	}
+
+	pointers := make([]string, 0, len(subs))
+	for _, sub := range subs {
+		pointers = append(pointers, sub.pointer)
+	}
+	return r.fail(path, keyword, V_ERR_NO_MATCH, pointers, schemaType(v))
+}
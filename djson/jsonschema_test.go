@@ -0,0 +1,271 @@
+package djson
+
+import (
+	"errors"
+	"testing"
+)
+
+const testJSONSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"properties": {
+		"id": {"type": "string", "format": "uuid"},
+		"name": {"type": "string", "minLength": 2, "maxLength": 4},
+		"age": {"type": "integer", "minimum": 0, "exclusiveMaximum": 150},
+		"price": {"type": "number", "multipleOf": 0.1},
+		"code": {"type": "string", "pattern": "^[A-Z]{3}$"},
+		"color": {"enum": ["red", "green", 1]},
+		"version": {"const": 2},
+		"hex": {"type": "string", "format": "HEX"},
+		"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 3, "uniqueItems": true},
+		"point": {"type": "array", "prefixItems": [{"type": "number"}, {"type": "number"}], "items": false},
+		"owner": {"$ref": "#/$defs/person"},
+		"contact": {"oneOf": [{"type": "string", "format": "email"}, {"type": "string", "format": "TELEPHONE"}]},
+		"nick": {"type": ["string", "null"]},
+		"note": {"not": {"type": "integer"}},
+		"level": {"anyOf": [{"type": "integer"}, {"type": "string", "maxLength": 1}]},
+		"extra": {"allOf": [{"minProperties": 1}, {"maxProperties": 2}]}
+	},
+	"required": ["id", "name"],
+	"additionalProperties": false,
+	"$defs": {
+		"person": {
+			"type": "object",
+			"properties": {
+				"name": {"type": "string"},
+				"friend": {"$ref": "#/$defs/person"}
+			},
+			"required": ["name"]
+		}
+	}
+}`
+
+func TestJSONSchema(t *testing.T) {
+	schema, err := CompileSchema(NewDJSON().Parse(testJSONSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valid := NewDJSON().Parse(`{
+		"id": "0F8FAD5B-D9CB-469F-A165-70867728950E",
+		"name": "abc",
+		"age": 30.0,
+		"price": 0.3,
+		"code": "ABC",
+		"color": 1.0,
+		"version": 2,
+		"hex": "0a1b",
+		"tags": ["a", "b"],
+		"point": [1, 2.5],
+		"owner": {"name": "a", "friend": {"name": "b"}},
+		"contact": "010-1234-5678",
+		"nick": null,
+		"note": "x",
+		"level": "a",
+		"extra": {"a": 1}
+	}`)
+
+	if errs := schema.Validate(valid); len(errs) != 0 || !schema.IsValid(valid) {
+		t.Fatal(errs)
+	}
+
+	invalid := NewDJSON().Parse(`{
+		"name": "abcde",
+		"age": 150,
+		"price": 0.35,
+		"code": "abc",
+		"color": "blue",
+		"version": 3,
+		"hex": "0a1",
+		"tags": ["a", "a"],
+		"point": [1, 2, 3],
+		"owner": {"friend": {}},
+		"contact": 1,
+		"nick": 1,
+		"note": 1,
+		"level": "ab",
+		"extra": {},
+		"unknown": true
+	}`)
+
+	if schema.IsValid(invalid) {
+		t.Fatal(invalid.ToString())
+	}
+
+	expected := map[string]string{
+		"/id":                V_ERR_REQUIRED,
+		"/name":              V_ERR_TOO_LONG,
+		"/age":               V_ERR_TOO_LARGE,
+		"/price":             V_ERR_MULTIPLE_OF,
+		"/code":              V_ERR_PATTERN,
+		"/color":             V_ERR_ENUM,
+		"/version":           V_ERR_CONST,
+		"/hex":               V_ERR_FORMAT,
+		"/tags/1":            V_ERR_NOT_UNIQUE,
+		"/point/2":           V_ERR_FALSE,
+		"/owner/name":        V_ERR_REQUIRED,
+		"/owner/friend/name": V_ERR_REQUIRED,
+		"/contact":           V_ERR_NO_MATCH,
+		"/nick":              V_ERR_TYPE,
+		"/note":              V_ERR_NOT,
+		"/level":             V_ERR_NO_MATCH,
+		"/extra":             V_ERR_TOO_FEW_PROPS,
+		"/unknown":           V_ERR_ADDITIONAL,
+	}
+
+	errs := schema.Validate(invalid)
+	if len(errs) != len(expected) {
+		t.Fatal(errs)
+	}
+	for _, e := range errs {
+		if expected[e.Path] != e.Code {
+			t.Fatal(e.Error())
+		}
+	}
+
+	for _, e := range errs {
+		switch e.Path {
+		case "/age":
+			if e.Rule != "exclusiveMaximum" {
+				t.Fatal(e.Error())
+			}
+		case "/contact":
+			if e.Rule != "oneOf" || e.ToDJSON().ToString() != `{"path":"/contact","rule":"oneOf","code":"no_match","expected":["/properties/contact/oneOf/0","/properties/contact/oneOf/1"],"actual":"integer"}` {
+				t.Fatal(e.ToDJSON().ToString())
+			}
+		case "/nick":
+			if e.Error() != "/nick: type_mismatch (expected [string null], got integer)" {
+				t.Fatal(e.Error())
+			}
+		}
+	}
+
+	contact := NewDJSON().Parse(`{"id": "0f8fad5b-d9cb-469f-a165-70867728950e", "name": "ab", "contact": "a@b"}`)
+	if errs := schema.Validate(contact); len(errs) != 1 || errs[0].Code != V_ERR_NO_MATCH {
+		t.Fatal(errs)
+	}
+}
+
+func TestJSONSchemaOneOf(t *testing.T) {
+	schema, err := CompileSchema(NewDJSON().Parse(`{"oneOf": [{"type": "integer"}, {"minimum": 0}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !schema.IsValid(NewDJSON().Parse(`-1`)) || !schema.IsValid(NewDJSON().Parse(`0.5`)) {
+		t.Fatal()
+	}
+
+	errs := schema.Validate(NewDJSON().Parse(`1`))
+	if len(errs) != 1 || errs[0].Code != V_ERR_MULTI_MATCH || errs[0].Actual != 2 {
+		t.Fatal(errs)
+	}
+
+	root, _ := CompileSchema(NewDJSON().Parse(`true`))
+	if !root.IsValid(nil) || !root.IsValid(NewDJSON().Parse(`{"a": 1}`)) {
+		t.Fatal()
+	}
+
+	root, _ = CompileSchema(NewDJSON().Parse(`false`))
+	if root.IsValid(NewDJSON().Parse(`1`)) {
+		t.Fatal()
+	}
+}
+
+func TestJSONSchemaFormats(t *testing.T) {
+	schema, err := CompileSchema(NewDJSON().Parse(`{"type": "array", "items": {"format": "even"}}`), &SchemaOptions{
+		Formats: map[string]func(string, ...int64) bool{
+			"even": func(s string, _ ...int64) bool { return len(s)%2 == 0 },
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !schema.IsValid(NewDJSON().Parse(`["ab", 1, "abcd"]`)) || schema.IsValid(NewDJSON().Parse(`["abc"]`)) {
+		t.Fatal()
+	}
+
+	unknown, _ := CompileSchema(NewDJSON().Parse(`{"format": "unknown"}`))
+	if !unknown.IsValid(NewDJSON().Parse(`"x"`)) {
+		t.Fatal()
+	}
+
+	dv := NewValidator()
+	err = dv.CompileSchema(`{"properties": {
+		"date": {"format": "date"},
+		"at": {"format": "date-time"},
+		"ip": {"format": "ipv4"},
+		"ip6": {"format": "ipv6"},
+		"uri": {"format": "uri"}
+	}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !dv.IsValid(NewDJSON().Parse(`{"date": "2024-02-29", "at": "2024-02-29T10:00:00+09:00", "ip": "10.0.0.1", "ip6": "::1", "uri": "https://example.com/a"}`)) {
+		t.Fatal()
+	}
+
+	report := dv.ValidationReport(NewDJSON().Parse(`{"date": "2023-02-29", "at": "2024-02-29", "ip": "::1", "ip6": "10.0.0.1", "uri": "/a"}`))
+	if report.Length() != 5 {
+		t.Fatal(report.ToString())
+	}
+}
+
+func TestJSONSchemaInvalid(t *testing.T) {
+	for _, s := range []string{
+		`1`,
+		`{"type": "integer1"}`,
+		`{"minLength": -1}`,
+		`{"maxLength": 1e300}`,
+		`{"maxItems": 9223372036854775808}`,
+		`{"pattern": "("}`,
+		`{"multipleOf": 0}`,
+		`{"anyOf": []}`,
+		`{"properties": {"a": 1}}`,
+		`{"$ref": "#/$defs/missing"}`,
+		`{"$ref": "https://example.com/schema"}`,
+		`{"required": [1]}`,
+		`{"$ref": "#"}`,
+		`{"$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"$ref": "#/$defs/a"}}, "$ref": "#/$defs/a"}`,
+		`{"$defs": {"a": {"anyOf": [{"type": "string"}, {"$ref": "#/$defs/b"}]}, "b": {"not": {"$ref": "#/$defs/a"}}}}`,
+	} {
+		if _, err := CompileSchema(NewDJSON().Parse(s)); !errors.Is(err, InvalidSchemaError) {
+			t.Fatal(s, err)
+		}
+	}
+
+	for _, doc := range []*DJSON{
+		NewDJSON().Parse(`{"maxItems": 9223372036854775807}`),
+		NewDJSON().SetLosslessNumbers(true).Parse(`{"maxItems": 9223372036854775807}`),
+	} {
+		if _, err := CompileSchema(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := CompileSchema(NewDJSON().SetLosslessNumbers(true).Parse(`{"maxLength": 1e300}`)); !errors.Is(err, InvalidSchemaError) {
+		t.Fatal(err)
+	}
+
+	dv := NewValidator()
+	if err := dv.CompileSchema(`{`); !errors.Is(err, InvalidSchemaError) {
+		t.Fatal(err)
+	}
+
+	recursive, err := CompileSchema(NewDJSON().Parse(`{"type": "array", "items": {"$ref": "#"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !recursive.IsValid(NewDJSON().Parse(`[[], [[]]]`)) || recursive.IsValid(NewDJSON().Parse(`[[1]]`)) {
+		t.Fatal()
+	}
+
+	tree, err := CompileSchema(NewDJSON().Parse(`{"$defs": {"node": {"allOf": [{"$ref": "#/$defs/leaf"}], "properties": {"next": {"$ref": "#/$defs/node"}}}, "leaf": {"type": "object"}}, "$ref": "#/$defs/node"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tree.IsValid(NewDJSON().Parse(`{"next": {"next": {}}}`)) || tree.IsValid(NewDJSON().Parse(`{"next": {"next": 1}}`)) {
+		t.Fatal()
+	}
+}
@@ -0,0 +1,767 @@
+package djson
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type DJSONMarshaler interface {
+	MarshalDJSON() (*DJSON, error)
+}
+
+type DJSONUnmarshaler interface {
+	UnmarshalDJSON(*DJSON) error
+}
+
+type FieldError struct {
+	Path     string
+	GoType   reflect.Type
+	JsonType string
+	Err      error
+}
+
+func (e *FieldError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "(root)"
+	}
+
+	if e.JsonType != "" {
+		return fmt.Sprintf("%s: %v: json %s into go %v", path, e.Err, e.JsonType, e.GoType)
+	}
+
+	return fmt.Sprintf("%s: %v: go %v", path, e.Err, e.GoType)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	djsonMarshalerType   = reflect.TypeOf((*DJSONMarshaler)(nil)).Elem()
+	djsonUnmarshalerType = reflect.TypeOf((*DJSONUnmarshaler)(nil)).Elem()
+	jsonMarshalerType    = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshalerType  = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textMarshalerType    = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType  = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	timeType             = reflect.TypeOf(time.Time{})
+	djsonPtrType         = reflect.TypeOf((*DJSON)(nil))
+	doPtrType            = reflect.TypeOf((*DO)(nil))
+	daPtrType            = reflect.TypeOf((*DA)(nil))
+)
+
+type structField struct {
+	name      string
+	index     []int
+	tagged    bool
+	omitEmpty bool
+	asString  bool
+}
+
+var structFieldCache sync.Map
+
+func typeFields(t reflect.Type) []*structField {
+	if cached, ok := structFieldCache.Load(t); ok {
+		return cached.([]*structField)
+	}
+
+	fields := make([]*structField, 0)
+	seen := make(map[string]bool)
+
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+
+	current := []embedded{{typ: t}}
+
+	for len(current) > 0 {
+		next := make([]embedded, 0)
+		level := make([]*structField, 0)
+		count := make(map[string]int)
+		taggedCount := make(map[string]int)
+
+		for _, each := range current {
+			for i := 0; i < each.typ.NumField(); i++ {
+				sf := each.typ.Field(i)
+
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+
+				name, opts, _ := strings.Cut(tag, ",")
+
+				index := make([]int, len(each.index)+1)
+				copy(index, each.index)
+				index[len(each.index)] = i
+
+				ft := sf.Type
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+
+				if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+					next = append(next, embedded{typ: ft, index: index})
+					continue
+				}
+
+				if !sf.IsExported() {
+					continue
+				}
+
+				tagged := name != ""
+				if !tagged {
+					name = sf.Name
+				}
+
+				if seen[name] {
+					continue
+				}
+
+				field := &structField{
+					name:   name,
+					index:  index,
+					tagged: tagged,
+				}
+
+				for _, opt := range strings.Split(opts, ",") {
+					switch opt {
+					case "omitempty":
+						field.omitEmpty = true
+					case "string":
+						field.asString = true
+					}
+				}
+
+				level = append(level, field)
+				count[name]++
+				if field.tagged {
+					taggedCount[name]++
+				}
+			}
+		}
+
+		// as in encoding/json, a name given by several fields at the same
+		// depth goes to the only tagged one, or to none of them
+		for _, field := range level {
+			if count[field.name] == 1 || (taggedCount[field.name] == 1 && field.tagged) {
+				fields = append(fields, field)
+			}
+			seen[field.name] = true
+		}
+
+		current = next
+	}
+
+	structFieldCache.Store(t, fields)
+	return fields
+}
+
+// fieldByIndex returns the field value, allocating nil embedded pointers
+// when alloc is set. ok is false if a nil embedded pointer is in the way.
+
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+
+	return v, true
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+
+	return false
+}
+
+func Marshal(v interface{}) (*DJSON, error) {
+	element, err := marshalValue([]interface{}{}, reflect.ValueOf(v), false, make(map[marshalVisit]bool))
+	if err != nil {
+		return nil, err
+	}
+
+	ret, _ := elementToDJSON(element)
+	return ret, nil
+}
+
+func marshalError(path []interface{}, t reflect.Type, err error) error {
+	return &FieldError{
+		Path:   BuildPath(path...),
+		GoType: t,
+		Err:    err,
+	}
+}
+
+// marshalVisit is a pointer, map or slice being marshaled; seen holds the
+// ones on the current path so that a cycle is an error, not a stack
+// overflow. The type and length tell apart values that share an address,
+// such as a struct and its first field.
+
+type marshalVisit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// marshalerValue returns v if it implements iface, or its address if only
+// the pointer does and v is addressable, as encoding/json does.
+
+func marshalerValue(v reflect.Value, iface reflect.Type) (reflect.Value, bool) {
+	if v.Type().Implements(iface) {
+		return v, true
+	}
+
+	if v.Kind() != reflect.Ptr && v.CanAddr() && v.Addr().Type().Implements(iface) {
+		return v.Addr(), true
+	}
+
+	return v, false
+}
+
+func marshalValue(path []interface{}, v reflect.Value, asString bool, seen map[marshalVisit]bool) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil, nil
+	}
+
+	t := v.Type()
+
+	switch t {
+	case djsonPtrType:
+		return v.Interface().(*DJSON).GetAsInterface(), nil
+	case doPtrType, daPtrType:
+		return v.Interface(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			break
+		}
+
+		visit := marshalVisit{ptr: v.Pointer(), typ: t}
+		if v.Kind() == reflect.Slice {
+			visit.len = v.Len()
+		}
+		if seen[visit] {
+			return nil, marshalError(path, t, fmt.Errorf("cycle: %w", UnsupportedValueError))
+		}
+		seen[visit] = true
+		defer delete(seen, visit)
+	}
+
+	if mv, ok := marshalerValue(v, djsonMarshalerType); ok {
+		dj, err := mv.Interface().(DJSONMarshaler).MarshalDJSON()
+		if err != nil {
+			return nil, marshalError(path, t, err)
+		}
+		if dj == nil {
+			return nil, nil
+		}
+		return dj.GetAsInterface(), nil
+	}
+
+	if t == timeType {
+		return v.Interface().(time.Time).Format(time.RFC3339Nano), nil
+	}
+
+	if mv, ok := marshalerValue(v, jsonMarshalerType); ok {
+		b, err := mv.Interface().(json.Marshaler).MarshalJSON()
+		if err != nil {
+			return nil, marshalError(path, t, err)
+		}
+		dj, err := parseDocument(string(b), false, false)
+		if err != nil {
+			return nil, marshalError(path, t, err)
+		}
+		return dj.GetAsInterface(), nil
+	}
+
+	if mv, ok := marshalerValue(v, textMarshalerType); ok {
+		b, err := mv.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, marshalError(path, t, err)
+		}
+		return string(b), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return marshalValue(path, v.Elem(), asString, seen)
+	case reflect.Bool:
+		if asString {
+			return strconv.FormatBool(v.Bool()), nil
+		}
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if asString {
+			return strconv.FormatInt(v.Int(), 10), nil
+		}
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if asString {
+			return strconv.FormatUint(v.Uint(), 10), nil
+		}
+		if v.Uint() > math.MaxInt64 {
+			return json.Number(strconv.FormatUint(v.Uint(), 10)), nil
+		}
+		return int64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, marshalError(path, t, UnsupportedValueError)
+		}
+		if asString {
+			return strconv.FormatFloat(f, 'g', -1, t.Bits()), nil
+		}
+		return f, nil
+	case reflect.String:
+		if asString {
+			b, _ := json.Marshal(v.String())
+			return string(b), nil
+		}
+		return v.String(), nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, nil
+		}
+
+		if t.Elem().Kind() == reflect.Uint8 && v.Kind() == reflect.Slice {
+			return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+		}
+
+		arr := NewArray()
+		for i := 0; i < v.Len(); i++ {
+			element, err := marshalValue(appendPath(path, i), v.Index(i), false, seen)
+			if err != nil {
+				return nil, err
+			}
+			arr.Element = append(arr.Element, element)
+		}
+		return arr, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+
+		obj := NewObject()
+		keys := v.MapKeys()
+		names := make([]string, len(keys))
+
+		for idx, key := range keys {
+			name, err := marshalMapKey(key)
+			if err != nil {
+				return nil, marshalError(path, t, err)
+			}
+			names[idx] = name
+		}
+
+		order := make([]int, len(keys))
+		for idx := range order {
+			order[idx] = idx
+		}
+		sort.Slice(order, func(i, j int) bool {
+			return names[order[i]] < names[order[j]]
+		})
+
+		for _, idx := range order {
+			element, err := marshalValue(appendPath(path, names[idx]), v.MapIndex(keys[idx]), false, seen)
+			if err != nil {
+				return nil, err
+			}
+			obj.set(names[idx], element)
+		}
+		return obj, nil
+	case reflect.Struct:
+		obj := NewObject()
+
+		for _, field := range typeFields(t) {
+			fv, ok := fieldByIndex(v, field.index, false)
+			if !ok {
+				continue
+			}
+
+			if field.omitEmpty && isEmptyValue(fv) {
+				continue
+			}
+
+			element, err := marshalValue(appendPath(path, field.name), fv, field.asString, seen)
+			if err != nil {
+				return nil, err
+			}
+			obj.set(field.name, element)
+		}
+		return obj, nil
+	}
+
+	return nil, marshalError(path, t, UnsupportedTypeError)
+}
+
+func marshalMapKey(key reflect.Value) (string, error) {
+	if key.Kind() == reflect.String {
+		return key.String(), nil
+	}
+
+	if tm, ok := key.Interface().(encoding.TextMarshaler); ok {
+		b, err := tm.MarshalText()
+		return string(b), err
+	}
+
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	}
+
+	return "", UnsupportedTypeError
+}
+
+func Unmarshal(dj *DJSON, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &FieldError{
+			GoType: reflect.TypeOf(v),
+			Err:    InvalidUnmarshalError,
+		}
+	}
+
+	if dj == nil {
+		dj = NewDJSON()
+	}
+
+	return unmarshalValue([]interface{}{}, dj.GetAsInterface(), rv.Elem(), false)
+}
+
+func unmarshalError(path []interface{}, element interface{}, t reflect.Type, err error) error {
+	jt, _ := elementToDJSON(element)
+	jsonType := ""
+	if jt != nil {
+		jsonType = jt.GetType()
+	}
+
+	return &FieldError{
+		Path:     BuildPath(path...),
+		GoType:   t,
+		JsonType: jsonType,
+		Err:      err,
+	}
+}
+
+func elementToJSONString(element interface{}) string {
+	switch t := element.(type) {
+	case *DO:
+		return t.ToString()
+	case *DA:
+		return t.ToString()
+	}
+
+	b, _ := json.Marshal(element)
+	return string(b)
+}
+
+func unmarshalValue(path []interface{}, element interface{}, v reflect.Value, fromString bool) error {
+	t := v.Type()
+
+	switch t {
+	case djsonPtrType:
+		dj, ok := elementToDJSON(element)
+		if !ok {
+			return unmarshalError(path, element, t, TypeMismatchError)
+		}
+		v.Set(reflect.ValueOf(dj))
+		return nil
+	case doPtrType, daPtrType:
+		ev := reflect.ValueOf(element)
+		if element != nil && ev.Type() != t {
+			return unmarshalError(path, element, t, TypeMismatchError)
+		}
+		if element == nil {
+			ev = reflect.Zero(t)
+		}
+		v.Set(ev)
+		return nil
+	}
+
+	if v.CanAddr() {
+		pv := v.Addr()
+
+		if pv.Type().Implements(djsonUnmarshalerType) {
+			dj, _ := elementToDJSON(element)
+			if err := pv.Interface().(DJSONUnmarshaler).UnmarshalDJSON(dj); err != nil {
+				return unmarshalError(path, element, t, err)
+			}
+			return nil
+		}
+
+		if t != timeType && pv.Type().Implements(jsonUnmarshalerType) {
+			if err := pv.Interface().(json.Unmarshaler).UnmarshalJSON([]byte(elementToJSONString(element))); err != nil {
+				return unmarshalError(path, element, t, err)
+			}
+			return nil
+		}
+
+		if pv.Type().Implements(textUnmarshalerType) {
+			if element == nil {
+				return nil
+			}
+			s, ok := element.(string)
+			if !ok {
+				return unmarshalError(path, element, t, TypeMismatchError)
+			}
+			if err := pv.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+				return unmarshalError(path, element, t, err)
+			}
+			return nil
+		}
+	}
+
+	if element == nil {
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+			v.Set(reflect.Zero(t))
+		}
+		return nil
+	}
+
+	if fromString {
+		s, ok := element.(string)
+		if !ok {
+			return unmarshalError(path, element, t, TypeMismatchError)
+		}
+
+		switch v.Kind() {
+		case reflect.String:
+			var us string
+			if err := json.Unmarshal([]byte(s), &us); err != nil {
+				return unmarshalError(path, element, t, TypeMismatchError)
+			}
+			element = us
+		case reflect.Ptr:
+		default:
+			parsed, err := parseDocument(s, false, false)
+			if err != nil {
+				return unmarshalError(path, element, t, TypeMismatchError)
+			}
+			element = parsed.GetAsInterface()
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		return unmarshalValue(path, element, v.Elem(), fromString)
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return unmarshalError(path, element, t, UnsupportedTypeError)
+		}
+		switch e := element.(type) {
+		case *DO:
+			v.Set(reflect.ValueOf(ConverObjectToMap(e)))
+		case *DA:
+			v.Set(reflect.ValueOf(ConvertArrayToSlice(e)))
+		default:
+			v.Set(reflect.ValueOf(e))
+		}
+		return nil
+	case reflect.Bool:
+		b, ok := element.(bool)
+		if !ok {
+			return unmarshalError(path, element, t, TypeMismatchError)
+		}
+		v.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if elementType(element) != JSON_INT {
+			return unmarshalError(path, element, t, TypeMismatchError)
+		}
+		b, ok := getBigIntBase(element)
+		if !ok || !b.IsInt64() || v.OverflowInt(b.Int64()) {
+			return unmarshalError(path, element, t, NumberRangeError)
+		}
+		v.SetInt(b.Int64())
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if elementType(element) != JSON_INT {
+			return unmarshalError(path, element, t, TypeMismatchError)
+		}
+		b, ok := getBigIntBase(element)
+		if !ok || !b.IsUint64() || v.OverflowUint(b.Uint64()) {
+			return unmarshalError(path, element, t, NumberRangeError)
+		}
+		v.SetUint(b.Uint64())
+		return nil
+	case reflect.Float32, reflect.Float64:
+		et := elementType(element)
+		if et != JSON_INT && et != JSON_FLOAT {
+			return unmarshalError(path, element, t, TypeMismatchError)
+		}
+		f, _ := getFloatBase(element)
+		if v.OverflowFloat(f) {
+			return unmarshalError(path, element, t, NumberRangeError)
+		}
+		v.SetFloat(f)
+		return nil
+	case reflect.String:
+		s, ok := element.(string)
+		if !ok {
+			return unmarshalError(path, element, t, TypeMismatchError)
+		}
+		v.SetString(s)
+		return nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			if s, ok := element.(string); ok {
+				b, err := base64.StdEncoding.DecodeString(s)
+				if err != nil {
+					return unmarshalError(path, element, t, TypeMismatchError)
+				}
+				v.SetBytes(b)
+				return nil
+			}
+		}
+
+		arr, ok := element.(*DA)
+		if !ok {
+			return unmarshalError(path, element, t, TypeMismatchError)
+		}
+
+		slice := reflect.MakeSlice(t, arr.Size(), arr.Size())
+		for idx := range arr.Element {
+			if err := unmarshalValue(appendPath(path, idx), arr.Element[idx], slice.Index(idx), false); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	case reflect.Array:
+		arr, ok := element.(*DA)
+		if !ok {
+			return unmarshalError(path, element, t, TypeMismatchError)
+		}
+
+		for idx := 0; idx < v.Len(); idx++ {
+			if idx >= arr.Size() {
+				v.Index(idx).Set(reflect.Zero(t.Elem()))
+				continue
+			}
+			if err := unmarshalValue(appendPath(path, idx), arr.Element[idx], v.Index(idx), false); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		obj, ok := element.(*DO)
+		if !ok {
+			return unmarshalError(path, element, t, TypeMismatchError)
+		}
+
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(t))
+		}
+
+		for _, k := range obj.Keys() {
+			key, err := unmarshalMapKey(k, t.Key())
+			if err != nil {
+				return unmarshalError(appendPath(path, k), obj.Map[k], t.Key(), err)
+			}
+
+			ev := reflect.New(t.Elem()).Elem()
+			if err := unmarshalValue(appendPath(path, k), obj.Map[k], ev, false); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, ev)
+		}
+		return nil
+	case reflect.Struct:
+		obj, ok := element.(*DO)
+		if !ok {
+			return unmarshalError(path, element, t, TypeMismatchError)
+		}
+
+		for _, field := range typeFields(t) {
+			ev, ok := obj.Map[field.name]
+			if !ok {
+				continue
+			}
+
+			fv, ok := fieldByIndex(v, field.index, true)
+			if !ok {
+				continue
+			}
+
+			if err := unmarshalValue(appendPath(path, field.name), ev, fv, field.asString); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return unmarshalError(path, element, t, UnsupportedTypeError)
+}
+
+func unmarshalMapKey(k string, t reflect.Type) (reflect.Value, error) {
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		kv := reflect.New(t)
+		if err := kv.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(k)); err != nil {
+			return reflect.Value{}, err
+		}
+		return kv.Elem(), nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(k).Convert(t), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(k, 10, 64)
+		if err != nil || reflect.Zero(t).OverflowInt(i) {
+			return reflect.Value{}, TypeMismatchError
+		}
+		return reflect.ValueOf(i).Convert(t), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(k, 10, 64)
+		if err != nil || reflect.Zero(t).OverflowUint(u) {
+			return reflect.Value{}, TypeMismatchError
+		}
+		return reflect.ValueOf(u).Convert(t), nil
+	}
+
+	return reflect.Value{}, UnsupportedTypeError
+}
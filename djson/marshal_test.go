@@ -0,0 +1,230 @@
+package djson
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+type marshalBase struct {
+	ID      int64     `json:"id"`
+	Created time.Time `json:"created"`
+}
+
+type marshalItem struct {
+	Name  string  `json:"name"`
+	Price float64 `json:"price,omitempty"`
+}
+
+type marshalLevel int
+
+func (l marshalLevel) MarshalDJSON() (*DJSON, error) {
+	return NewDJSON().Put([]string{"low", "high"}[l]), nil
+}
+
+func (l *marshalLevel) UnmarshalDJSON(dj *DJSON) error {
+	switch dj.GetAsString() {
+	case "low":
+		*l = 0
+	case "high":
+		*l = 1
+	default:
+		return errors.New("unknown level")
+	}
+	return nil
+}
+
+type marshalOrder struct {
+	marshalBase
+	Customer string            `json:"customer"`
+	Count    int               `json:"count,string"`
+	Items    []marshalItem     `json:"items"`
+	Meta     map[string]string `json:"meta,omitempty"`
+	Note     *string           `json:"note"`
+	Level    marshalLevel      `json:"level"`
+	Secret   string            `json:"-"`
+	Extra    interface{}       `json:"extra,omitempty"`
+}
+
+type marshalPtrLevel int
+
+func (l *marshalPtrLevel) MarshalDJSON() (*DJSON, error) {
+	return NewDJSON().Put(int(*l) * 10), nil
+}
+
+type marshalNode struct {
+	Name string       `json:"name"`
+	Next *marshalNode `json:"next"`
+}
+
+func TestMarshal(t *testing.T) {
+	created := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	order := marshalOrder{
+		marshalBase: marshalBase{ID: 7, Created: created},
+		Customer:    "kim",
+		Count:       2,
+		Items:       []marshalItem{{Name: "pen", Price: 1.5}, {Name: "gift"}},
+		Level:       1,
+		Secret:      "hidden",
+	}
+
+	dj, err := Marshal(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"count":"2","created":"2024-03-01T09:30:00Z","customer":"kim","id":7,"items":[{"name":"pen","price":1.5},{"name":"gift"}],"level":"high","note":null}`
+	if !dj.Equal(NewDJSON().Parse(expected)) {
+		t.Fatal(dj.ToString())
+	}
+
+	var decoded marshalOrder
+	if err := Unmarshal(dj, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.ID != 7 || !decoded.Created.Equal(created) || decoded.Count != 2 || decoded.Level != 1 {
+		t.Fatal(decoded)
+	}
+
+	if len(decoded.Items) != 2 || decoded.Items[0].Price != 1.5 || decoded.Items[1].Name != "gift" {
+		t.Fatal(decoded.Items)
+	}
+
+	var generic map[string]interface{}
+	if err := Unmarshal(NewDJSON().Parse(`{"a":[1,"b"],"c":{"d":true}}`), &generic); err != nil {
+		t.Fatal(err)
+	}
+
+	if generic["c"].(map[string]interface{})["d"] != true {
+		t.Fatal(generic)
+	}
+}
+
+type marshalName struct {
+	Name string `json:"name"`
+}
+
+type marshalTitle struct {
+	Title string
+	Code  string
+}
+
+type marshalLabel struct {
+	Title string
+	Label string `json:"Code"`
+}
+
+func TestMarshalEdge(t *testing.T) {
+	dj, err := Marshal(struct {
+		Max  uint64   `json:"max"`
+		List []uint64 `json:"list"`
+	}{math.MaxUint64, []uint64{math.MaxUint64, 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dj.ToString() != `{"list":[18446744073709551615,1],"max":18446744073709551615}` || dj.Clone().ToString() != dj.ToString() {
+		t.Fatal(dj.ToString())
+	}
+	if b := dj.GetAsBigInt("max"); b == nil || !b.IsUint64() || b.Uint64() != math.MaxUint64 {
+		t.Fatal(b)
+	}
+
+	var back struct {
+		Max uint64 `json:"max"`
+	}
+	if err := Unmarshal(dj, &back); err != nil || back.Max != math.MaxUint64 {
+		t.Fatal(back, err)
+	}
+
+	// Title is given twice at the same depth and dropped; for Code the only
+	// tagged field wins
+	dup := struct {
+		marshalName
+		marshalTitle
+		marshalLabel
+	}{marshalName{"a"}, marshalTitle{"b", "c"}, marshalLabel{"d", "e"}}
+
+	dj, _ = Marshal(dup)
+	std, _ := json.Marshal(dup)
+	if dj.ToString() != `{"Code":"e","name":"a"}` || !dj.Equal(NewDJSON().Parse(string(std))) {
+		t.Fatal(dj.ToString(), string(std))
+	}
+}
+
+func TestUnmarshalError(t *testing.T) {
+	var order marshalOrder
+
+	err := Unmarshal(NewDJSON().Parse(`{"items":[{"name":"pen"},{"name":"cup","price":"free"}]}`), &order)
+
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || !errors.Is(err, TypeMismatchError) {
+		t.Fatal(err)
+	}
+
+	if fieldErr.Path != `["items"][1]["price"]` || fieldErr.JsonType != "string" {
+		t.Fatal(fieldErr)
+	}
+
+	var small struct {
+		V int8 `json:"v"`
+	}
+	if err := Unmarshal(NewDJSON().Parse(`{"v":300}`), &small); !errors.Is(err, NumberRangeError) {
+		t.Fatal(err)
+	}
+
+	if err := Unmarshal(NewDJSON().Parse(`{"level":"medium"}`), &order); err == nil {
+		t.Fatal("expected level error")
+	}
+
+	if err := Unmarshal(NewDJSON(), order); !errors.Is(err, InvalidUnmarshalError) {
+		t.Fatal(err)
+	}
+
+	if _, err := Marshal(map[string]interface{}{"f": make(chan int)}); !errors.Is(err, UnsupportedTypeError) {
+		t.Fatal(err)
+	}
+}
+
+func TestMarshalPointerReceiver(t *testing.T) {
+	v := struct {
+		Level marshalPtrLevel   `json:"level"`
+		List  []marshalPtrLevel `json:"list"`
+	}{2, []marshalPtrLevel{3}}
+
+	// as with encoding/json, the pointer method is used only where the value
+	// is addressable: through a pointer or in a slice
+	dj, err := Marshal(&v)
+	if err != nil || dj.ToString() != `{"level":20,"list":[30]}` {
+		t.Fatal(dj.ToString(), err)
+	}
+
+	if dj, err = Marshal(v); err != nil || dj.ToString() != `{"level":2,"list":[30]}` {
+		t.Fatal(dj.ToString(), err)
+	}
+}
+
+func TestMarshalCycle(t *testing.T) {
+	a := &marshalNode{Name: "a"}
+	a.Next = &marshalNode{Name: "b", Next: a}
+
+	if _, err := Marshal(a); !errors.Is(err, UnsupportedValueError) {
+		t.Fatal(err)
+	}
+
+	m := map[string]interface{}{}
+	m["self"] = m
+	if _, err := Marshal(m); !errors.Is(err, UnsupportedValueError) {
+		t.Fatal(err)
+	}
+
+	// a value shared by two fields is not a cycle
+	shared := &marshalNode{Name: "c"}
+	dj, err := Marshal([]*marshalNode{shared, shared})
+	if err != nil || dj.ToString() != `[{"name":"c","next":null},{"name":"c","next":null}]` {
+		t.Fatal(dj.ToString(), err)
+	}
+}
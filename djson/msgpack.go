@@ -0,0 +1,388 @@
+package djson
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// MessagePack encoding. Binary values decode to base64 text and the
+// timestamp extension decodes to an RFC 3339 string. The format has no
+// bignum, so writing an integer that does not fit in 64 bits fails with
+// UnsupportedValueError rather than losing precision as a float.
+
+const msgpackTimestampExt = -1
+
+// msgpackSizes gives the size of the length or value following each code.
+
+var msgpackSizes = map[byte]int{
+	0xc4: 1, 0xc5: 2, 0xc6: 4,
+	0xc7: 1, 0xc8: 2, 0xc9: 4,
+	0xca: 4, 0xcb: 8,
+	0xcc: 1, 0xcd: 2, 0xce: 4, 0xcf: 8,
+	0xd0: 1, 0xd1: 2, 0xd2: 4, 0xd3: 8,
+	0xd9: 1, 0xda: 2, 0xdb: 4,
+	0xdc: 2, 0xdd: 4, 0xde: 2, 0xdf: 4,
+}
+
+func (m *DJSON) ToMsgPack() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeMsgPack(&buf, m.GetAsInterface()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (m *DO) ToMsgPack() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeMsgPack(&buf, m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (m *DA) ToMsgPack() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeMsgPack(&buf, m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ParseMsgPack decodes a single MessagePack object. On error m is left
+// unchanged.
+
+func (m *DJSON) ParseMsgPack(data []byte) (*DJSON, error) {
+	r := &binaryReader{data: data}
+
+	element, err := readMsgPack(r, 0)
+	if err != nil {
+		return m, err
+	}
+
+	ret, err := binaryResult(element, r)
+	if err != nil {
+		return m, err
+	}
+
+	*m = *ret
+	return m, nil
+}
+
+func (m *DO) ParseMsgPack(data []byte) (*DO, error) {
+	ret, err := NewDJSON().ParseMsgPack(data)
+	if err != nil {
+		return m, err
+	}
+
+	if !ret.IsObject() {
+		return m, NotObjectError
+	}
+
+	*m = *ret.Object
+	return m, nil
+}
+
+func (m *DA) ParseMsgPack(data []byte) (*DA, error) {
+	ret, err := NewDJSON().ParseMsgPack(data)
+	if err != nil {
+		return m, err
+	}
+
+	if !ret.IsArray() {
+		return m, NotArrayError
+	}
+
+	*m = *ret.Array
+	return m, nil
+}
+
+// writeMsgPackHead writes a length header choosing the fix, 8, 16 or 32 bit
+// form. fixMax is zero for types without a fix form; code8 zero for types
+// without an 8 bit form.
+
+func writeMsgPackHead(buf *bytes.Buffer, n int, fixBase byte, fixMax int, code8 byte, code16 byte, code32 byte) {
+	switch {
+	case n <= fixMax:
+		buf.WriteByte(fixBase | byte(n))
+	case code8 != 0 && n <= math.MaxUint8:
+		buf.WriteByte(code8)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(code16)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	default:
+		buf.WriteByte(code32)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+}
+
+func writeMsgPackString(buf *bytes.Buffer, s string) {
+	writeMsgPackHead(buf, len(s), 0xa0, 31, 0xd9, 0xda, 0xdb)
+	buf.WriteString(s)
+}
+
+func writeMsgPackInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0 && i <= math.MaxInt8:
+		buf.WriteByte(byte(i))
+	case i < 0 && i >= -32:
+		buf.WriteByte(byte(int8(i)))
+	case i >= 0 && i <= math.MaxUint8:
+		buf.WriteByte(0xcc)
+		buf.WriteByte(byte(i))
+	case i >= 0 && i <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(i)))
+	case i >= 0 && i <= math.MaxUint32:
+		buf.WriteByte(0xce)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(i)))
+	case i >= 0:
+		buf.WriteByte(0xcf)
+		buf.Write(binary.BigEndian.AppendUint64(nil, uint64(i)))
+	case i >= math.MinInt8:
+		buf.WriteByte(0xd0)
+		buf.WriteByte(byte(int8(i)))
+	case i >= math.MinInt16:
+		buf.WriteByte(0xd1)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(int16(i))))
+	case i >= math.MinInt32:
+		buf.WriteByte(0xd2)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(int32(i))))
+	default:
+		buf.WriteByte(0xd3)
+		buf.Write(binary.BigEndian.AppendUint64(nil, uint64(i)))
+	}
+}
+
+func writeMsgPackFloat(buf *bytes.Buffer, f float64) {
+	if f32 := float32(f); float64(f32) == f {
+		buf.WriteByte(0xca)
+		buf.Write(binary.BigEndian.AppendUint32(nil, math.Float32bits(f32)))
+		return
+	}
+
+	buf.WriteByte(0xcb)
+	buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(f)))
+}
+
+func writeMsgPack(buf *bytes.Buffer, element interface{}) error {
+	switch t := element.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if t {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case string:
+		writeMsgPackString(buf, t)
+	case DO:
+		return writeMsgPack(buf, &t)
+	case DA:
+		return writeMsgPack(buf, &t)
+	case *DJSON:
+		return writeMsgPack(buf, t.GetAsInterface())
+	case *DO:
+		keys := t.Keys()
+		writeMsgPackHead(buf, len(keys), 0x80, 15, 0, 0xde, 0xdf)
+		for _, k := range keys {
+			writeMsgPackString(buf, k)
+			if err := writeMsgPack(buf, t.Map[k]); err != nil {
+				return err
+			}
+		}
+	case *DA:
+		writeMsgPackHead(buf, len(t.Element), 0x90, 15, 0, 0xdc, 0xdd)
+		for idx := range t.Element {
+			if err := writeMsgPack(buf, t.Element[idx]); err != nil {
+				return err
+			}
+		}
+	default:
+		et := elementType(element)
+		if et != JSON_INT && et != JSON_FLOAT {
+			return UnsupportedValueError
+		}
+
+		kind, i, u, _, f := binaryNumber(element)
+		switch kind {
+		case binaryNumInt:
+			writeMsgPackInt(buf, i)
+		case binaryNumUint:
+			buf.WriteByte(0xcf)
+			buf.Write(binary.BigEndian.AppendUint64(nil, u))
+		case binaryNumBig:
+			return UnsupportedValueError
+		case binaryNumFloat:
+			writeMsgPackFloat(buf, f)
+		}
+	}
+
+	return nil
+}
+
+func readMsgPackArray(r *binaryReader, n uint64, depth int) (interface{}, error) {
+	arr := NewArray()
+	arr.Element = make([]interface{}, 0, r.capacity(n))
+
+	for idx := uint64(0); idx < n; idx++ {
+		v, err := readMsgPack(r, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		arr.Element = append(arr.Element, v)
+	}
+
+	return arr, nil
+}
+
+func readMsgPackMap(r *binaryReader, n uint64, depth int) (interface{}, error) {
+	obj := NewObject()
+
+	for idx := uint64(0); idx < n; idx++ {
+		k, err := readMsgPack(r, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		key, err := binaryMapKey(r, k)
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := readMsgPack(r, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		obj.set(key, v)
+	}
+
+	return obj, nil
+}
+
+func readMsgPackString(r *binaryReader, n uint64) (interface{}, error) {
+	b, err := r.read(n)
+	if err != nil {
+		return nil, err
+	}
+	if !utf8.Valid(b) {
+		return nil, r.errorf(InvalidBinaryError, "invalid UTF-8 in string")
+	}
+	return string(b), nil
+}
+
+func readMsgPackExt(r *binaryReader, n uint64) (interface{}, error) {
+	typ, err := r.readByte()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := r.read(n)
+	if err != nil {
+		return nil, err
+	}
+
+	if int8(typ) != msgpackTimestampExt {
+		return nil, r.errorf(InvalidBinaryError, "unsupported extension type %d", int8(typ))
+	}
+
+	var ts time.Time
+	switch len(data) {
+	case 4:
+		ts = time.Unix(int64(binary.BigEndian.Uint32(data)), 0)
+	case 8:
+		v := binary.BigEndian.Uint64(data)
+		ts = time.Unix(int64(v&0x3ffffffff), int64(v>>34))
+	case 12:
+		ts = time.Unix(int64(binary.BigEndian.Uint64(data[4:])), int64(binary.BigEndian.Uint32(data)))
+	default:
+		return nil, r.errorf(InvalidBinaryError, "invalid timestamp length %d", len(data))
+	}
+
+	return ts.UTC().Format(time.RFC3339Nano), nil
+}
+
+func readMsgPack(r *binaryReader, depth int) (interface{}, error) {
+	if depth > binaryMaxDepth {
+		return nil, r.errorf(InvalidBinaryError, "nesting too deep")
+	}
+
+	c, err := r.readByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return readMsgPackMap(r, uint64(c&0x0f), depth)
+	case c&0xf0 == 0x90:
+		return readMsgPackArray(r, uint64(c&0x0f), depth)
+	case c&0xe0 == 0xa0:
+		return readMsgPackString(r, uint64(c&0x1f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return readMsgPackExt(r, 1<<(c-0xd4))
+	}
+
+	size, ok := msgpackSizes[c]
+	if !ok {
+		return nil, r.errorf(InvalidBinaryError, "invalid type code 0x%02x", c)
+	}
+
+	n, err := r.readUint(size)
+	if err != nil {
+		return nil, err
+	}
+
+	switch c {
+	case 0xc4, 0xc5, 0xc6:
+		b, err := r.read(n)
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString(b), nil
+	case 0xc7, 0xc8, 0xc9:
+		return readMsgPackExt(r, n)
+	case 0xca:
+		return binaryFloat(r, float64(math.Float32frombits(uint32(n))))
+	case 0xcb:
+		return binaryFloat(r, math.Float64frombits(n))
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		if n > math.MaxInt64 {
+			return json.Number(strconv.FormatUint(n, 10)), nil
+		}
+		return int64(n), nil
+	case 0xd0:
+		return int64(int8(n)), nil
+	case 0xd1:
+		return int64(int16(n)), nil
+	case 0xd2:
+		return int64(int32(n)), nil
+	case 0xd3:
+		return int64(n), nil
+	case 0xd9, 0xda, 0xdb:
+		return readMsgPackString(r, n)
+	case 0xdc, 0xdd:
+		return readMsgPackArray(r, n, depth)
+	}
+
+	return readMsgPackMap(r, n, depth)
+}
@@ -0,0 +1,403 @@
+package djson
+
+import (
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// In lossless number mode the parser keeps numbers as json.Number, so that
+// integers beyond int64 and decimals keep their original literal, and so do
+// objects and arrays created in that mode when a json.Number is put in
+// them. Int and Float accessors still return the nearest int64 and float64.
+
+var losslessNumbers atomic.Bool
+
+func SetLosslessNumbers(on bool) {
+	losslessNumbers.Store(on)
+}
+
+func IsLosslessNumbers() bool {
+	return losslessNumbers.Load()
+}
+
+// isNumberLiteral reports whether s is a number in JSON grammar.
+
+func isNumberLiteral(s string) bool {
+	idx := 0
+	digits := func() int {
+		start := idx
+		for idx < len(s) && s[idx] >= '0' && s[idx] <= '9' {
+			idx++
+		}
+		return idx - start
+	}
+
+	if idx < len(s) && s[idx] == '-' {
+		idx++
+	}
+
+	if n := digits(); n == 0 || (n > 1 && s[idx-n] == '0') {
+		return false
+	}
+
+	if idx < len(s) && s[idx] == '.' {
+		idx++
+		if digits() == 0 {
+			return false
+		}
+	}
+
+	if idx < len(s) && (s[idx] == 'e' || s[idx] == 'E') {
+		idx++
+		if idx < len(s) && (s[idx] == '+' || s[idx] == '-') {
+			idx++
+		}
+		if digits() == 0 {
+			return false
+		}
+	}
+
+	return idx == len(s)
+}
+
+func isIntLiteral(n json.Number) bool {
+	return !strings.ContainsAny(string(n), ".eE")
+}
+
+// decimalMaxExponent bounds how far a literal is written out as plain
+// digits or made an exact big.Rat. A literal such as 1e99999999 would take
+// millions of digits, so beyond it those conversions fail and callers fall
+// back to float64; equality and order use decimal parts instead.
+
+const decimalMaxExponent = 1000
+
+// decimal is a number as 0.digits × 10^exp, digits without leading or
+// trailing zeros and empty for zero.
+
+type decimal struct {
+	neg    bool
+	digits string
+	exp    int
+}
+
+func parseDecimal(lit string) (decimal, bool) {
+	if !isNumberLiteral(lit) {
+		return decimal{}, false
+	}
+
+	var d decimal
+	if strings.HasPrefix(lit, "-") {
+		d.neg = true
+		lit = lit[1:]
+	}
+
+	mantissa, exp := lit, 0
+	if idx := strings.IndexAny(lit, "eE"); idx >= 0 {
+		e, err := strconv.ParseInt(lit[idx+1:], 10, 32)
+		if err != nil {
+			return decimal{}, false
+		}
+		mantissa, exp = lit[:idx], int(e)
+	}
+
+	intPart, fracPart, _ := strings.Cut(mantissa, ".")
+	digits := strings.TrimRight(intPart+fracPart, "0")
+	point := len(intPart) + exp
+
+	for len(digits) > 0 && digits[0] == '0' {
+		digits = digits[1:]
+		point--
+	}
+
+	if digits == "" {
+		return decimal{}, true
+	}
+
+	d.digits, d.exp = digits, point
+	return d, true
+}
+
+func decimalOf(v interface{}) (decimal, bool) {
+	switch t := v.(type) {
+	case json.Number:
+		return parseDecimal(string(t))
+	case float32:
+		return parseDecimal(strconv.FormatFloat(float64(t), 'e', -1, 32))
+	case float64:
+		return parseDecimal(strconv.FormatFloat(t, 'e', -1, 64))
+	}
+
+	if IsIntType(v) {
+		s, _ := getStringBase(v)
+		return parseDecimal(s)
+	}
+
+	return decimal{}, false
+}
+
+func (d decimal) sign() int {
+	switch {
+	case d.digits == "":
+		return 0
+	case d.neg:
+		return -1
+	}
+	return 1
+}
+
+func compareDecimal(a, b decimal) int {
+	as, bs := a.sign(), b.sign()
+	switch {
+	case as < bs:
+		return -1
+	case as > bs:
+		return 1
+	case as == 0:
+		return 0
+	}
+
+	mag := strings.Compare(a.digits, b.digits)
+	switch {
+	case a.exp < b.exp:
+		mag = -1
+	case a.exp > b.exp:
+		mag = 1
+	}
+
+	return mag * as
+}
+
+func numberRat(v interface{}) (*big.Rat, bool) {
+	s, ok := getDecimalBase(v)
+	if !ok {
+		return nil, false
+	}
+	return new(big.Rat).SetString(s)
+}
+
+// numberEqual compares numbers exactly when at least one side is a
+// json.Number. handled is false if neither side is one.
+
+func numberEqual(a, b interface{}) (equal bool, handled bool) {
+	_, aok := a.(json.Number)
+	_, bok := b.(json.Number)
+	if !aok && !bok {
+		return false, false
+	}
+
+	ad, aok := decimalOf(a)
+	bd, bok := decimalOf(b)
+	if !aok || !bok {
+		return false, true
+	}
+
+	return compareDecimal(ad, bd) == 0, true
+}
+
+func getBigIntBase(v interface{}) (*big.Int, bool) {
+	switch t := v.(type) {
+	case uint64:
+		return new(big.Int).SetUint64(t), true
+	case uint:
+		return new(big.Int).SetUint64(uint64(t)), true
+	case json.Number:
+		if isIntLiteral(t) {
+			return new(big.Int).SetString(string(t), 10)
+		}
+	}
+
+	if IsIntType(v) {
+		i, _ := getIntBase(v)
+		return big.NewInt(i), true
+	}
+
+	r, ok := numberRat(v)
+	if !ok || !r.IsInt() {
+		return nil, false
+	}
+
+	return new(big.Int).Set(r.Num()), true
+}
+
+func getBigFloatBase(v interface{}) (*big.Float, bool) {
+	if n, ok := v.(json.Number); ok {
+		prec := uint(len(n))*4 + 64
+		f, _, err := big.ParseFloat(string(n), 10, prec, big.ToNearestEven)
+		if err != nil {
+			return nil, false
+		}
+		return f, true
+	}
+
+	if b, ok := getBigIntBase(v); ok && IsIntType(v) {
+		return new(big.Float).SetInt(b), true
+	}
+
+	if IsFloatType(v) {
+		f, _ := getFloatBase(v)
+		return big.NewFloat(f), true
+	}
+
+	return nil, false
+}
+
+// getDecimalBase returns a number as a plain decimal string without
+// exponent. Literals keep their digits, including trailing zeros; those
+// whose point lies beyond decimalMaxExponent are not written out.
+
+func getDecimalBase(v interface{}) (string, bool) {
+	switch t := v.(type) {
+	case json.Number:
+		return expandDecimal(string(t))
+	case float32:
+		return strconv.FormatFloat(float64(t), 'f', -1, 32), true
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64), true
+	}
+
+	if IsIntType(v) {
+		return getStringBase(v)
+	}
+
+	return "", false
+}
+
+func expandDecimal(lit string) (string, bool) {
+	if !isNumberLiteral(lit) {
+		return "", false
+	}
+
+	sign := ""
+	if strings.HasPrefix(lit, "-") {
+		sign = "-"
+		lit = lit[1:]
+	}
+
+	mantissa, exp := lit, 0
+	if idx := strings.IndexAny(lit, "eE"); idx >= 0 {
+		e, err := strconv.ParseInt(lit[idx+1:], 10, 32)
+		if err != nil {
+			return "", false
+		}
+		mantissa, exp = lit[:idx], int(e)
+	}
+
+	intPart, fracPart, _ := strings.Cut(mantissa, ".")
+	digits := intPart + fracPart
+	point := len(intPart) + exp
+	if point > decimalMaxExponent || point < -decimalMaxExponent {
+		return "", false
+	}
+
+	var ret string
+	switch {
+	case point <= 0:
+		ret = "0." + strings.Repeat("0", -point) + digits
+	case point >= len(digits):
+		ret = digits + strings.Repeat("0", point-len(digits))
+	default:
+		ret = digits[:point] + "." + digits[point:]
+	}
+
+	for len(ret) > 1 && ret[0] == '0' && ret[1] != '.' {
+		ret = ret[1:]
+	}
+
+	return sign + ret, true
+}
+
+// SetLosslessNumbers selects lossless number mode for this document. It
+// applies to objects and arrays already in the document, whose numbers are
+// kept as they are, and to those created later by Parse and Put.
+
+func (m *DJSON) SetLosslessNumbers(on bool) *DJSON {
+	m.lossless = on
+	setElementLossless(m.GetAsInterface(), on)
+	return m
+}
+
+func setElementLossless(element interface{}, on bool) {
+	switch t := element.(type) {
+	case *DO:
+		t.lossless = on
+		for _, v := range t.Map {
+			setElementLossless(v, on)
+		}
+	case *DA:
+		t.lossless = on
+		for idx := range t.Element {
+			setElementLossless(t.Element[idx], on)
+		}
+	}
+}
+
+// GetAsBigInt returns nil if the value is not an integer.
+
+func (m *DJSON) GetAsBigInt(key ...interface{}) *big.Int {
+	if b, ok := getBigIntBase(m.getNumberElement(key...)); ok {
+		return b
+	}
+	return nil
+}
+
+func (m *DJSON) GetAsBigFloat(key ...interface{}) *big.Float {
+	if f, ok := getBigFloatBase(m.getNumberElement(key...)); ok {
+		return f
+	}
+	return nil
+}
+
+func (m *DJSON) GetAsDecimalString(key ...interface{}) (string, bool) {
+	return getDecimalBase(m.getNumberElement(key...))
+}
+
+func (m *DJSON) getNumberElement(key ...interface{}) interface{} {
+	if IsEmptyArg(key) {
+		return m.GetAsInterface()
+	}
+
+	switch tkey := key[0].(type) {
+	case string:
+		if m.JsonType == JSON_OBJECT {
+			v, _ := m.Object.Get(tkey)
+			return v
+		}
+	case int:
+		if m.JsonType == JSON_ARRAY {
+			v, _ := m.Array.Get(tkey)
+			return v
+		}
+	}
+
+	return nil
+}
+
+func (m *DO) GetAsBigInt(key string) (*big.Int, bool) {
+	return getBigIntBase(m.Map[key])
+}
+
+func (m *DO) GetAsBigFloat(key string) (*big.Float, bool) {
+	return getBigFloatBase(m.Map[key])
+}
+
+func (m *DO) GetAsDecimalString(key string) (string, bool) {
+	return getDecimalBase(m.Map[key])
+}
+
+func (m *DA) GetAsBigInt(idx int) (*big.Int, bool) {
+	v, _ := m.Get(idx)
+	return getBigIntBase(v)
+}
+
+func (m *DA) GetAsBigFloat(idx int) (*big.Float, bool) {
+	v, _ := m.Get(idx)
+	return getBigFloatBase(v)
+}
+
+func (m *DA) GetAsDecimalString(idx int) (string, bool) {
+	v, _ := m.Get(idx)
+	return getDecimalBase(v)
+}
@@ -0,0 +1,165 @@
+package djson
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLosslessNumbers(t *testing.T) {
+	doc := `{"id":12345678901234567890,"price":19.90,"tiny":1.5e-30,"big":[-9223372036854775809,1E+3]}`
+
+	aJson := NewDJSON().SetLosslessNumbers(true).SetOrdered(true).Parse(doc)
+	if aJson.ToString() != doc {
+		t.Fatal(aJson.ToString())
+	}
+
+	if id := aJson.GetAsBigInt("id"); id == nil || id.String() != "12345678901234567890" {
+		t.Fatal(id)
+	}
+
+	if !aJson.IsInt("id") || !aJson.IsFloat("price") {
+		t.Fatal("unexpected number types")
+	}
+
+	if price, ok := aJson.GetAsDecimalString("price"); !ok || price != "19.90" {
+		t.Fatal(price)
+	}
+
+	if tiny, _ := aJson.Object.GetAsDecimalString("tiny"); tiny != "0.0000000000000000000000000000015" {
+		t.Fatal(tiny)
+	}
+
+	if aJson.GetAsFloat("price") != 19.9 {
+		t.Fatal(aJson.GetAsFloat("price"))
+	}
+
+	arr, _ := aJson.Object.GetAsArray("big")
+	if b, ok := arr.GetAsBigInt(0); !ok || b.String() != "-9223372036854775809" {
+		t.Fatal(b)
+	}
+
+	if b, ok := arr.GetAsBigInt(1); !ok || b.Int64() != 1000 {
+		t.Fatal(b)
+	}
+
+	if f, ok := arr.GetAsBigFloat(1); !ok || f.String() != "1000" {
+		t.Fatal(f)
+	}
+
+	cloned := aJson.Clone()
+	if cloned.ToString() != doc || !cloned.Equal(aJson) {
+		t.Fatal(cloned.ToString())
+	}
+
+	if !NewDJSON().Parse(`{"a":1.0}`).Equal(NewDJSON().SetLosslessNumbers(true).Parse(`{"a":1.00}`)) {
+		t.Fatal("numerically equal literals must be equal")
+	}
+
+	scalar := NewDJSON().SetLosslessNumbers(true).Parse(`98765432109876543210`)
+	if scalar.ToString() != `98765432109876543210` || scalar.GetAsBigInt().String() != `98765432109876543210` {
+		t.Fatal(scalar.ToString())
+	}
+
+	strict, err := NewDJSON().SetLosslessNumbers(true).ParseWithError(`[0.10, 7]`)
+	if err != nil || strict.ToString() != `[0.10,7]` {
+		t.Fatal(strict.ToString(), err)
+	}
+
+	var target struct {
+		ID uint64 `json:"id"`
+	}
+	if err := Unmarshal(aJson, &target); err != nil || target.ID != 12345678901234567890 {
+		t.Fatal(target, err)
+	}
+}
+
+func TestLosslessNumbersPut(t *testing.T) {
+	obj := NewObject().Put("a", json.Number("1.50")).Put("b", json.Number("7"))
+	if v, ok := obj.Map["a"].(float64); !ok || v != 1.5 {
+		t.Fatal(obj.Map["a"])
+	}
+	if v, ok := obj.Map["b"].(int64); !ok || v != 7 {
+		t.Fatal(obj.Map["b"])
+	}
+
+	arr := NewArray().PushBack(json.Number("2.50"))
+	if arr.ReplaceAt(0, json.Number("3.0")); arr.Element[0] != 3.0 {
+		t.Fatal(arr.Element[0])
+	}
+
+	aJson := NewDJSON().Parse(`{"a":{"b":[1]}}`).SetLosslessNumbers(true)
+	aJson.Put("c", json.Number("1.50"))
+	sub, _ := aJson.Object.GetAsObject("a")
+	sub.Put("d", json.Number("0.10"))
+	list, _ := sub.GetAsArray("b")
+	list.PushBack(json.Number("2.0"))
+
+	if aJson.ToString() != `{"a":{"b":[1,2.0],"d":0.10},"c":1.50}` {
+		t.Fatal(aJson.ToString())
+	}
+
+	aJson.SetLosslessNumbers(false).Put("e", json.Number("1.50"))
+	if aJson.ToString() != `{"a":{"b":[1,2.0],"d":0.10},"c":1.50,"e":1.5}` {
+		t.Fatal(aJson.ToString())
+	}
+}
+
+func TestLosslessNumbersParseContainer(t *testing.T) {
+	aJson := NewDJSON().Parse(`{"a":{},"b":[]}`).SetLosslessNumbers(true)
+
+	obj, _ := aJson.Object.GetAsObject("a")
+	if _, err := obj.ParseWithError(`{"price":19.90,"id":12345678901234567890}`); err != nil {
+		t.Fatal(err)
+	}
+	if price, ok := obj.GetAsDecimalString("price"); !ok || price != "19.90" {
+		t.Fatal(price)
+	}
+
+	arr, _ := aJson.Object.GetAsArray("b")
+	if _, err := arr.ParseWithError(`[0.10,12345678901234567890]`); err != nil {
+		t.Fatal(err)
+	}
+	if b, ok := arr.GetAsBigInt(1); !ok || b.String() != "12345678901234567890" {
+		t.Fatal(b)
+	}
+
+	if aJson.ToString() != `{"a":{"id":12345678901234567890,"price":19.90},"b":[0.10,12345678901234567890]}` {
+		t.Fatal(aJson.ToString())
+	}
+}
+
+func TestLosslessNumbersHugeExponent(t *testing.T) {
+	aJson := NewDJSON().SetLosslessNumbers(true).Parse(`[1e99999999, 10e99999998, 1e99999998, -1e-99999999, 0e99999999]`)
+
+	if !aJson.Equal(NewDJSON().SetLosslessNumbers(true).Parse(`[1e99999999, 1e99999999, 0.1e99999999, -1e-99999999, 0]`)) {
+		t.Fatal(aJson.ToString())
+	}
+
+	if aJson.Equal(NewDJSON().SetLosslessNumbers(true).Parse(`[1e99999999, 1e99999999, 1e99999999, -1e-99999999, 0]`)) {
+		t.Fatal(aJson.ToString())
+	}
+
+	if _, ok := aJson.GetAsDecimalString(0); ok {
+		t.Fatal("expected no plain decimal for 1e99999999")
+	}
+
+	if s, ok := NewDJSON().SetLosslessNumbers(true).Parse(`[1.50e3]`).GetAsDecimalString(0); !ok || s != "1500" {
+		t.Fatal(s)
+	}
+
+	if compareElements(aJson.Array.Element[2], aJson.Array.Element[0]) != -1 || compareElements(aJson.Array.Element[3], aJson.Array.Element[4]) != -1 {
+		t.Fatal(aJson.ToString())
+	}
+
+	if err := aJson.ApplyPatchString(`[{"op": "test", "path": "/0", "value": 1e99999998}]`); err == nil {
+		t.Fatal("expected the test op to fail")
+	}
+
+	schema, err := CompileSchema(NewDJSON().SetLosslessNumbers(true).Parse(`{"type": "array", "items": {"minimum": -1e99999999, "maximum": 1e99999999}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !schema.IsValid(aJson) || schema.IsValid(NewDJSON().SetLosslessNumbers(true).Parse(`[1.1e99999999]`)) {
+		t.Fatal(schema.Validate(aJson))
+	}
+}
@@ -4,21 +4,142 @@ import (
 	"encoding/json"
 	"math"
 	"reflect"
+	"sort"
+	"sync/atomic"
 
 	"github.com/volatiletech/null/v8"
 )
 
+// An ordered DO remembers the insertion order of its keys and serializes
+// them in that order. Unordered objects serialize with sorted keys. A
+// lossless DO stores json.Number values as they are; others convert them
+// to int64 or float64.
+
 type DO struct {
-	Map map[string]interface{}
+	Map      map[string]interface{}
+	ordered  bool
+	lossless bool
+	keys     []string
+}
+
+var orderedObjects atomic.Bool
+
+// SetOrderedObjects selects whether objects created from now on, including
+// those created by parsing, preserve insertion order.
+
+func SetOrderedObjects(on bool) {
+	orderedObjects.Store(on)
+}
+
+func IsOrderedObjects() bool {
+	return orderedObjects.Load()
 }
 
 func NewObject() *DO {
 	return &DO{
-		Map: make(map[string]interface{}),
+		Map:      make(map[string]interface{}),
+		ordered:  orderedObjects.Load(),
+		lossless: losslessNumbers.Load(),
+	}
+}
+
+func NewOrderedObject() *DO {
+	return NewObject().SetOrdered(true)
+}
+
+// SetOrdered switches the ordering mode. Keys already present keep their
+// current order, which is sorted when the object was unordered.
+
+func (m *DO) SetOrdered(on bool) *DO {
+	if on && !m.ordered {
+		m.keys = m.Keys()
+	}
+
+	if !on {
+		m.keys = nil
+	}
+
+	m.ordered = on
+	return m
+}
+
+func (m *DO) IsOrdered() bool {
+	return m.ordered
+}
+
+// Keys returns the keys in insertion order for ordered objects and sorted
+// otherwise. Keys written to Map directly are listed last, sorted.
+
+func (m *DO) Keys() []string {
+	keys := make([]string, 0, len(m.Map))
+
+	if m.ordered {
+		seen := make(map[string]bool, len(m.keys))
+		for _, k := range m.keys {
+			if _, ok := m.Map[k]; ok && !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+
+		if len(keys) == len(m.Map) {
+			return keys
+		}
+
+		rest := make([]string, 0, len(m.Map)-len(keys))
+		for k := range m.Map {
+			if !seen[k] {
+				rest = append(rest, k)
+			}
+		}
+		sort.Strings(rest)
+
+		return append(keys, rest...)
+	}
+
+	for k := range m.Map {
+		keys = append(keys, k)
 	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (m *DO) ParseWithError(doc string) (*DO, error) {
+	ret, err := parseDocument(doc, m.ordered, m.lossless)
+	if err != nil {
+		return m, err
+	}
+
+	if ret.JsonType != JSON_OBJECT {
+		return m, newParseErrorAt(doc, firstValueOffset(doc)+1, NotObjectError, "not object")
+	}
+
+	*m = *ret.Object
+	return m, nil
+}
+
+// set stores a value the decoders or Marshal produced as it is, as they
+// append to arrays, so that json.Number for integers beyond int64 is kept
+// whatever the mode of the object.
+
+func (m *DO) set(key string, value interface{}) {
+	if _, ok := m.Map[key]; !ok && m.ordered {
+		m.keys = append(m.keys, key)
+	}
+	m.Map[key] = value
 }
 
 func (m *DO) Put(key string, value interface{}) *DO {
+	if m.ordered {
+		if _, ok := m.Map[key]; !ok {
+			defer func() {
+				if _, ok := m.Map[key]; ok {
+					m.keys = append(m.keys, key)
+				}
+			}()
+		}
+	}
 
 	if IsFloatType(value) {
 		switch t := value.(type) {
@@ -41,6 +162,12 @@ func (m *DO) Put(key string, value interface{}) *DO {
 	}
 
 	if n, ok := value.(json.Number); ok {
+		if m.lossless {
+			if isNumberLiteral(string(n)) {
+				m.Map[key] = n
+			}
+			return m
+		}
 		if i, err := n.Int64(); err == nil {
 			m.Map[key] = i
 			return m
@@ -195,7 +322,12 @@ func (m *DO) GetType(key string) (string, bool) {
 		return "", false
 	}
 
-	switch value.(type) {
+	switch t := value.(type) {
+	case json.Number:
+		if isIntLiteral(t) {
+			return "int", true
+		}
+		return "float", true
 	case DA, *DA:
 		return "array", true
 	case DO, *DO:
@@ -296,21 +428,26 @@ func (m *DO) Remove(keys ...string) *DO {
 	for idx := range keys {
 		delete(m.Map, keys[idx])
 	}
+
+	if m.ordered {
+		remain := m.keys[:0]
+		for _, k := range m.keys {
+			if _, ok := m.Map[k]; ok {
+				remain = append(remain, k)
+			}
+		}
+		m.keys = remain
+	}
+
 	return m
 }
 
 func (m *DO) ToStringPretty() string {
-	jsonByte, _ := json.MarshalIndent(ConverObjectToMap(m), "", "   ")
-	return string(jsonByte)
+	return encodeElementIndent(m)
 }
 
 func (m *DO) ToString() string {
-	jsonByte, err := json.Marshal(ConverObjectToMap(m))
-	if err != nil {
-		// log.Println(err)
-		return ""
-	}
-	return string(jsonByte)
+	return encodeElement(m)
 }
 
 func (m *DO) Length() int {
@@ -335,6 +472,13 @@ func (m *DO) Equal(t *DO) bool {
 			return false
 		}
 
+		if eq, ok := numberEqual(m.Map[i], t.Map[i]); ok {
+			if !eq {
+				return false
+			}
+			continue
+		}
+
 		mtype := reflect.TypeOf(m.Map[i]).String()
 		ttype := reflect.TypeOf(t.Map[i]).String()
 
@@ -395,6 +539,11 @@ func (m *DO) Clone() *DO {
 	t := NewObject()
 
 	t.Map = make(map[string]interface{})
+	t.ordered = m.ordered
+	t.lossless = m.lossless
+	if m.ordered {
+		t.keys = m.Keys()
+	}
 
 	for k := range m.Map {
 
@@ -412,8 +561,10 @@ func (m *DO) Clone() *DO {
 			t.Map[k] = m.Map[k].(bool)
 		case "int", "uint", "int8", "uint8", "int16", "uint16", "int32", "uint32", "int64", "uint64":
 			t.Map[k], _ = m.GetAsInt(k)
-		case "Map", "float64":
+		case "float32", "float64":
 			t.Map[k], _ = m.GetAsFloat(k)
+		case "json.Number":
+			t.Map[k] = m.Map[k]
 		case "*djson.DO":
 			mdo := m.Map[k].(*DO)
 			t.Map[k] = mdo.Clone()
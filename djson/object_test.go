@@ -0,0 +1,65 @@
+package djson
+
+import (
+	"testing"
+)
+
+func TestOrderedObject(t *testing.T) {
+	doc := `{"zeta":1,"alpha":{"y":true,"b":[{"k2":1,"k1":2}]},"mid":"x"}`
+
+	aJson := NewDJSON().SetOrdered(true).Parse(doc)
+	if aJson.ToString() != doc {
+		t.Fatal(aJson.ToString())
+	}
+
+	aJson.Put("beta", 2)
+	aJson.Remove("zeta")
+	aJson.Put("zeta", 3)
+	if aJson.ToString() != `{"alpha":{"y":true,"b":[{"k2":1,"k1":2}]},"mid":"x","beta":2,"zeta":3}` {
+		t.Fatal(aJson.ToString())
+	}
+
+	cloned := aJson.Clone()
+	cloned.Put("first", nil)
+	if cloned.ToString() != `{"alpha":{"y":true,"b":[{"k2":1,"k1":2}]},"mid":"x","beta":2,"zeta":3,"first":null}` {
+		t.Fatal(cloned.ToString())
+	}
+
+	sorted := NewDJSON().Parse(doc)
+	if sorted.ToString() != `{"alpha":{"b":[{"k1":2,"k2":1}],"y":true},"mid":"x","zeta":1}` {
+		t.Fatal(sorted.ToString())
+	}
+
+	if !sorted.Equal(NewDJSON().SetOrdered(true).Parse(doc)) {
+		t.Fatal("order must not affect Equal")
+	}
+
+	strict, err := NewDJSON().SetOrdered(true).ParseWithError(doc)
+	if err != nil || strict.ToString() != doc {
+		t.Fatal(strict.ToString(), err)
+	}
+
+	if aJson.Object.ToStringPretty() != "{\n   \"alpha\": {\n      \"y\": true,\n      \"b\": [\n         {\n            \"k2\": 1,\n            \"k1\": 2\n         }\n      ]\n   },\n   \"mid\": \"x\",\n   \"beta\": 2,\n   \"zeta\": 3\n}" {
+		t.Fatal(aJson.Object.ToStringPretty())
+	}
+}
+
+func TestOrderedObjectsGlobal(t *testing.T) {
+	SetOrderedObjects(true)
+	defer SetOrderedObjects(false)
+
+	obj := NewObject().Put("b", 1).Put("a", 2)
+	if obj.ToString() != `{"b":1,"a":2}` {
+		t.Fatal(obj.ToString())
+	}
+
+	aJson := NewDJSON().Parse(`[{"c":1,"b":2}]`)
+	if aJson.ToString() != `[{"c":1,"b":2}]` {
+		t.Fatal(aJson.ToString())
+	}
+
+	keys := NewOrderedObject().Put("y", 1).Put("x", 2).Keys()
+	if len(keys) != 2 || keys[0] != "y" || keys[1] != "x" {
+		t.Fatal(keys)
+	}
+}
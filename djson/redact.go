@@ -0,0 +1,200 @@
+package djson
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/lokks307/go-util/mt"
+)
+
+const (
+	REDACT_REMOVE    = iota
+	REDACT_MASK      // replace the value with Mask
+	REDACT_KEEP_LAST // keep the last KeepLast characters, mask the rest; mask all if no longer
+	REDACT_HASH      // hex SHA-256 of Salt and the value
+	REDACT_RRN       // resident registration number, as mt.GetMaskedRRN
+	REDACT_TELEPHONE // hyphened telephone number with the middle part masked
+)
+
+const defaultRedactMask = "****"
+
+// RedactRule applies a strategy to the values matched by Path, a JSON
+// pointer in which "*" matches any single key or index and "**" any number
+// of them, e.g. "/**/password" or "/users/*/phone". The first matching
+// rule wins. Strategies on strings fall back to Mask for objects and
+// arrays.
+
+type RedactRule struct {
+	Path     string
+	Strategy int
+	Mask     string
+	MaskChar rune
+	KeepLast int
+	Salt     string
+}
+
+type Redactor struct {
+	rules  []*RedactRule
+	tokens [][]string
+}
+
+// NewRedactor compiles rules. Rules whose Path is not a JSON pointer are
+// reported with InvalidPointerError.
+
+func NewRedactor(rules ...*RedactRule) (*Redactor, error) {
+	r := &Redactor{
+		rules:  make([]*RedactRule, 0, len(rules)),
+		tokens: make([][]string, 0, len(rules)),
+	}
+
+	for _, rule := range rules {
+		tokens, err := ParsePointer(rule.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		r.rules = append(r.rules, rule)
+		r.tokens = append(r.tokens, tokens)
+	}
+
+	return r, nil
+}
+
+// Redact returns a masked clone of m; m itself is not changed.
+
+func (r *Redactor) Redact(m *DJSON) *DJSON {
+	ret := m.Clone()
+
+	ret.Walk(func(node *WalkNode) int {
+		rule := r.match(node.Path)
+		if rule == nil {
+			return WALK_CONTINUE
+		}
+
+		if rule.Strategy == REDACT_REMOVE {
+			return WALK_REMOVE
+		}
+
+		node.Replace(rule.apply(node.Value))
+		return WALK_CONTINUE
+	})
+
+	return ret
+}
+
+// Redact is a shorthand for NewRedactor(rules...).Redact(m).
+
+func (m *DJSON) Redact(rules ...*RedactRule) (*DJSON, error) {
+	r, err := NewRedactor(rules...)
+	if err != nil {
+		return nil, err
+	}
+	return r.Redact(m), nil
+}
+
+func (r *Redactor) match(path []interface{}) *RedactRule {
+	for idx := range r.rules {
+		if matchRedactPath(r.tokens[idx], path) {
+			return r.rules[idx]
+		}
+	}
+	return nil
+}
+
+func matchRedactPath(tokens []string, path []interface{}) bool {
+	if len(tokens) == 0 {
+		return len(path) == 0
+	}
+
+	if tokens[0] == "**" {
+		for skip := 0; skip <= len(path); skip++ {
+			if matchRedactPath(tokens[1:], path[skip:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(path) == 0 {
+		return false
+	}
+
+	if tokens[0] != "*" {
+		switch t := path[0].(type) {
+		case int:
+			if tokens[0] != strconv.Itoa(t) {
+				return false
+			}
+		case string:
+			if tokens[0] != t {
+				return false
+			}
+		}
+	}
+
+	return matchRedactPath(tokens[1:], path[1:])
+}
+
+func (rule *RedactRule) mask() string {
+	if rule.Mask == "" {
+		return defaultRedactMask
+	}
+	return rule.Mask
+}
+
+func (rule *RedactRule) maskChar() string {
+	if rule.MaskChar == 0 {
+		return "*"
+	}
+	return string(rule.MaskChar)
+}
+
+func (rule *RedactRule) apply(value *DJSON) interface{} {
+	if rule.Strategy == REDACT_HASH {
+		sum := sha256.Sum256([]byte(rule.Salt + redactString(value)))
+		return hex.EncodeToString(sum[:])
+	}
+
+	if value.IsObject() || value.IsArray() || rule.Strategy == REDACT_MASK {
+		return rule.mask()
+	}
+
+	if value.IsNull() {
+		return nil
+	}
+
+	s := redactString(value)
+
+	switch rule.Strategy {
+	case REDACT_KEEP_LAST:
+		runes := []rune(s)
+		keep := rule.KeepLast
+		if keep >= len(runes) {
+			keep = 0
+		}
+		if keep < 0 {
+			keep = 0
+		}
+		return strings.Repeat(rule.maskChar(), len(runes)-keep) + string(runes[len(runes)-keep:])
+	case REDACT_RRN:
+		return mt.GetMaskedRRN(s)
+	case REDACT_TELEPHONE:
+		parts := strings.Split(mt.GetHypenedTelephone(s), "-")
+		if len(parts) != 3 {
+			return rule.mask()
+		}
+		parts[1] = strings.Repeat(rule.maskChar(), len(parts[1]))
+		return strings.Join(parts, "-")
+	}
+
+	return rule.mask()
+}
+
+func redactString(value *DJSON) string {
+	if value.IsString() {
+		return value.String
+	}
+	return value.ToString()
+}
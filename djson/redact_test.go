@@ -0,0 +1,64 @@
+package djson
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	aJson := NewDJSON().Parse(`{
+		"user": {"name": "kim", "rrn": "9001011234567", "phone": "01012345678", "card": "1234567812345678"},
+		"auth": {"token": "secret", "nested": {"password": "pw"}},
+		"orders": [{"id": 1, "memo": "call me"}, {"id": 2, "memo": "leave at door"}],
+		"password": "top"
+	}`)
+
+	r, err := NewRedactor(
+		&RedactRule{Path: "/user/rrn", Strategy: REDACT_RRN},
+		&RedactRule{Path: "/user/phone", Strategy: REDACT_TELEPHONE},
+		&RedactRule{Path: "/user/card", Strategy: REDACT_KEEP_LAST, KeepLast: 4},
+		&RedactRule{Path: "/auth/token", Strategy: REDACT_HASH, Salt: "s"},
+		&RedactRule{Path: "/**/password", Strategy: REDACT_MASK},
+		&RedactRule{Path: "/orders/*/memo", Strategy: REDACT_REMOVE},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ret := r.Redact(aJson)
+
+	expected := map[string]string{
+		`["user"]["name"]`:               "kim",
+		`["user"]["rrn"]`:                "900101-1******",
+		`["user"]["phone"]`:              "010-****-5678",
+		`["user"]["card"]`:               "************5678",
+		`["auth"]["nested"]["password"]`: "****",
+		`["password"]`:                   "****",
+	}
+	for path, value := range expected {
+		if ret.GetAsStringPath(path) != value {
+			t.Fatalf("%s: expected %s, got %s", path, value, ret.GetAsStringPath(path))
+		}
+	}
+
+	if token := ret.GetAsStringPath(`["auth"]["token"]`); len(token) != 64 || token == "secret" {
+		t.Fatal(token)
+	}
+
+	if ret.HasPointer("/orders/0/memo") || ret.HasPointer("/orders/1/memo") || !ret.HasPointer("/orders/1/id") {
+		t.Fatal(ret.ToString())
+	}
+
+	if aJson.GetAsStringPath(`["user"]["rrn"]`) != "9001011234567" || !aJson.HasPointer("/orders/0/memo") {
+		t.Fatal("source document was changed")
+	}
+
+	if _, err := aJson.Redact(&RedactRule{Path: "user"}); !errors.Is(err, InvalidPointerError) {
+		t.Fatal(err)
+	}
+
+	pin, _ := NewDJSON().Parse(`{"pin":"1234","code":"12"}`).Redact(&RedactRule{Path: "/*", Strategy: REDACT_KEEP_LAST, KeepLast: 4})
+	if pin.GetAsString("pin") != "****" || pin.GetAsString("code") != "**" {
+		t.Fatal(pin.ToString())
+	}
+}
@@ -0,0 +1,248 @@
+package djson
+
+import (
+	"math"
+)
+
+// inferFormats are the string formats InferSchema detects, most specific
+// first. A format is chosen only if every sample string passes its check.
+
+var inferFormats = []struct {
+	name  string
+	check func(string, ...int64) bool
+	sized bool // min and max apply, otherwise the format fixes them
+}{
+	{"UUID", CheckFuncUUID, false},
+	{"EMAIL", CheckFuncEmail, false},
+	{"YYYYMMDD", CheckFuncYYYYMMDD, false},
+	{"HHMMSS", CheckFuncHHMMSS, false},
+	{"TELEPHONE", CheckTelephone, false},
+	{"DEC", CheckFuncDec, true},
+	{"HEX", CheckFuncHex, true},
+}
+
+type schemaNode struct {
+	nulls   int
+	ints    int
+	floats  int
+	strings int
+	bools   int
+	objects int
+	arrays  int
+
+	minInt, maxInt     int64
+	minFloat, maxFloat float64
+	minLen, maxLen     int64
+	minSize, maxSize   int64
+	formats            []bool
+
+	keys     []string
+	children map[string]*schemaNode
+	presence map[string]int
+	items    *schemaNode
+}
+
+func newSchemaNode() *schemaNode {
+	return &schemaNode{
+		minInt:   math.MaxInt64,
+		maxInt:   math.MinInt64,
+		minFloat: math.Inf(1),
+		maxFloat: math.Inf(-1),
+		minLen:   math.MaxInt64,
+		minSize:  math.MaxInt64,
+		children: make(map[string]*schemaNode),
+		presence: make(map[string]int),
+	}
+}
+
+func (n *schemaNode) add(element interface{}) {
+	switch elementType(element) {
+	case JSON_NULL:
+		n.nulls++
+	case JSON_INT:
+		n.ints++
+		if i, ok := getIntBase(element); ok {
+			if i < n.minInt {
+				n.minInt = i
+			}
+			if i > n.maxInt {
+				n.maxInt = i
+			}
+		}
+	case JSON_FLOAT:
+		n.floats++
+
+		f, _ := getFloatBase(element)
+		if f < n.minFloat {
+			n.minFloat = f
+		}
+		if f > n.maxFloat {
+			n.maxFloat = f
+		}
+	case JSON_BOOL:
+		n.bools++
+	case JSON_STRING:
+		s, _ := element.(string)
+		n.strings++
+
+		if l := int64(len(s)); l < n.minLen {
+			n.minLen = l
+		}
+		if l := int64(len(s)); l > n.maxLen {
+			n.maxLen = l
+		}
+
+		if n.formats == nil {
+			n.formats = make([]bool, len(inferFormats))
+			for idx := range n.formats {
+				n.formats[idx] = true
+			}
+		}
+		for idx := range inferFormats {
+			n.formats[idx] = n.formats[idx] && s != "" && inferFormats[idx].check(s)
+		}
+	case JSON_OBJECT:
+		n.objects++
+
+		obj, _ := element.(*DO)
+		for _, k := range obj.Keys() {
+			child, ok := n.children[k]
+			if !ok {
+				child = newSchemaNode()
+				n.children[k] = child
+				n.keys = append(n.keys, k)
+			}
+			n.presence[k]++
+			child.add(obj.Map[k])
+		}
+	case JSON_ARRAY:
+		n.arrays++
+
+		arr, _ := element.(*DA)
+		if size := int64(arr.Size()); size < n.minSize {
+			n.minSize = size
+		}
+		if size := int64(arr.Size()); size > n.maxSize {
+			n.maxSize = size
+		}
+
+		if n.items == nil {
+			n.items = newSchemaNode()
+		}
+		for idx := range arr.Element {
+			n.items.add(arr.Element[idx])
+		}
+	}
+}
+
+// alternatives returns the syntax of each JSON type seen at the node.
+
+func (n *schemaNode) alternatives() []*DO {
+	alts := make([]*DO, 0)
+
+	if n.ints > 0 {
+		alts = append(alts, NewOrderedObject().Put("type", "INT").Put("min", n.minInt).Put("max", n.maxInt))
+	}
+
+	if n.floats > 0 {
+		alts = append(alts, NewOrderedObject().Put("type", "FLOAT").Put("min", n.minFloat).Put("max", n.maxFloat))
+	}
+
+	if n.strings > 0 {
+		item := NewOrderedObject().Put("type", "STRING")
+		sized := true
+
+		for idx := range inferFormats {
+			if n.formats[idx] {
+				item.Put("type", inferFormats[idx].name)
+				sized = inferFormats[idx].sized
+				break
+			}
+		}
+
+		if sized {
+			item.Put("min", n.minLen).Put("max", n.maxLen)
+		}
+		alts = append(alts, item)
+	}
+
+	if n.bools > 0 {
+		alts = append(alts, NewOrderedObject().Put("type", "BOOL"))
+	}
+
+	if n.objects > 0 {
+		props := NewOrderedObject()
+		for _, k := range n.keys {
+			child := n.children[k].syntax()
+			if n.presence[k] == n.objects {
+				if obj, ok := child.(*DO); ok {
+					obj.Put("required", true)
+				}
+			}
+			props.Put(k, child)
+		}
+		alts = append(alts, NewOrderedObject().Put("type", "OBJECT").Put("object", props))
+	}
+
+	if n.arrays > 0 {
+		item := NewOrderedObject().Put("type", "ARRAY").Put("min", n.minSize).Put("max", n.maxSize)
+		if n.items != nil && n.items.seen() {
+			item.Put("array", n.items.syntax())
+		}
+		alts = append(alts, item)
+	}
+
+	return alts
+}
+
+func (n *schemaNode) seen() bool {
+	return n.nulls+n.ints+n.floats+n.strings+n.bools+n.objects+n.arrays > 0
+}
+
+// syntax returns an object for a single type and an array of alternatives
+// for mixed types. The syntax has no null type, so a value that was null
+// in any sample gets an item without a type, which takes any value.
+
+func (n *schemaNode) syntax() interface{} {
+	if n.nulls > 0 {
+		return NewOrderedObject()
+	}
+
+	alts := n.alternatives()
+
+	if len(alts) == 0 {
+		return NewOrderedObject()
+	}
+
+	if len(alts) == 1 {
+		return alts[0]
+	}
+
+	arr := NewArray()
+	for _, alt := range alts {
+		arr.Element = append(arr.Element, alt)
+	}
+	return arr
+}
+
+// InferSchema derives a Validator syntax from sample documents. Types and
+// min/max bounds cover all samples; object keys present in every sample
+// are required, and strings that all match a known format (UUID, EMAIL,
+// YYYYMMDD, HHMMSS, TELEPHONE, DEC, HEX) get that type. Fields of mixed
+// types, ints and floats included, become a list of alternatives, which
+// cannot be required. Values that were null in some sample are left
+// without a type, so that every sample passes the result.
+
+func InferSchema(samples ...*DJSON) (*DJSON, error) {
+	if len(samples) == 0 {
+		return nil, NoSampleError
+	}
+
+	root := newSchemaNode()
+	for _, sample := range samples {
+		root.add(sample.GetAsInterface())
+	}
+
+	ret, _ := elementToDJSON(root.syntax())
+	return ret, nil
+}
@@ -0,0 +1,118 @@
+package djson
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestInferSchema(t *testing.T) {
+	samples := []*DJSON{
+		NewDJSON().Parse(`{"id":"0f8fad5b-d9cb-469f-8165-70867728950e","email":"kim@example.com","age":30,"score":1,"birth":"19900101","tags":["a","bb"],"memo":null,"code":"ff00","gone":null}`),
+		NewDJSON().Parse(`{"id":"7c9e6679-7425-40de-944b-e07fc1f90ae7","email":"lee@example.co.kr","age":41,"score":2.5,"birth":"1983-12-31","tags":[],"memo":"hi","code":"0a1b2c","nick":"lee"}`),
+	}
+
+	schema, err := InferSchema(samples...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"type":"OBJECT","object":{` +
+		`"age":{"type":"INT","min":30,"max":41,"required":true},` +
+		`"birth":{"type":"YYYYMMDD","required":true},` +
+		`"code":{"type":"HEX","min":4,"max":6,"required":true},` +
+		`"email":{"type":"EMAIL","required":true},` +
+		`"gone":{},` +
+		`"id":{"type":"UUID","required":true},` +
+		`"memo":{"required":true},` +
+		`"score":[{"type":"INT","min":1,"max":1},{"type":"FLOAT","min":2.5,"max":2.5}],` +
+		`"tags":{"type":"ARRAY","min":0,"max":2,"array":{"type":"STRING","min":1,"max":2},"required":true},` +
+		`"nick":{"type":"STRING","min":3,"max":3}}}`
+	if schema.ToString() != expected {
+		t.Fatalf("expected %s, got %s", expected, schema.ToString())
+	}
+
+	dv := NewValidator()
+	if !dv.Compile(schema.ToString()) {
+		t.Fatal("schema does not compile")
+	}
+
+	for _, sample := range samples {
+		if errs := dv.Validate(sample); len(errs) != 0 {
+			t.Fatal(sample.ToString(), errs)
+		}
+	}
+
+	bad := samples[0].Clone()
+	bad.Put("email", "not an email")
+	if dv.IsValid(bad) {
+		t.Fatal(bad.ToString())
+	}
+
+	bad = samples[0].Clone()
+	bad.Remove("memo")
+	if dv.IsValid(bad) {
+		t.Fatal(bad.ToString())
+	}
+
+	bad = samples[0].Clone()
+	bad.Remove("age")
+	if dv.IsValid(bad) {
+		t.Fatal(bad.ToString())
+	}
+
+	if _, err := InferSchema(); !errors.Is(err, NoSampleError) {
+		t.Fatal(err)
+	}
+}
+
+func TestInferSchemaMixed(t *testing.T) {
+	schema, _ := InferSchema(NewDJSON().Parse(`[1, "a", true, null]`))
+
+	if schema.ToString() != `{"type":"ARRAY","min":4,"max":4,"array":{}}` {
+		t.Fatal(schema.ToString())
+	}
+
+	schema, _ = InferSchema(NewDJSON().Parse(`[1, "a", 2.5]`))
+
+	if schema.ToString() != `{"type":"ARRAY","min":3,"max":3,"array":[{"type":"INT","min":1,"max":1},{"type":"FLOAT","min":2.5,"max":2.5},{"type":"STRING","min":1,"max":1}]}` {
+		t.Fatal(schema.ToString())
+	}
+
+	dv := NewValidator()
+	dv.Compile(schema.ToString())
+	if !dv.IsValid(NewDJSON().Parse(`[2.5, "b", 1]`)) || dv.IsValid(NewDJSON().Parse(`[1, {}, 2.5]`)) {
+		t.Fatal(schema.ToString())
+	}
+}
+
+func TestInferSchemaNulls(t *testing.T) {
+	samples := []*DJSON{
+		NewDJSON().Parse(`{"n":"x","list":[1,null],"sub":{"a":null}}`),
+		NewDJSON().Parse(`{"n":null,"list":[2],"sub":null}`),
+		NewDJSON().Parse(`{"list":null}`),
+	}
+
+	schema, _ := InferSchema(samples...)
+	if schema.ToString() != `{"type":"OBJECT","object":{"list":{"required":true},"n":{},"sub":{}}}` {
+		t.Fatal(schema.ToString())
+	}
+
+	dv := NewValidator()
+	if !dv.Compile(schema.ToString()) {
+		t.Fatal(schema.ToString())
+	}
+
+	for _, sample := range samples {
+		if errs := dv.Validate(sample); len(errs) != 0 {
+			t.Fatal(sample.ToString(), errs)
+		}
+	}
+
+	schema, _ = InferSchema(samples[0], samples[1])
+	dv.Compile(schema.ToString())
+	for _, sample := range samples[:2] {
+		if errs := dv.Validate(sample); len(errs) != 0 {
+			t.Fatal(schema.ToString(), sample.ToString(), errs)
+		}
+	}
+}
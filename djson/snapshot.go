@@ -0,0 +1,427 @@
+package djson
+
+import (
+	"math/big"
+	"sync"
+	"sync/atomic"
+)
+
+// Snapshot is a read-only copy of a document. Nothing writes to the tree
+// behind a snapshot, so any number of goroutines may read it at once.
+// Values handed out are either scalars, sub snapshots of the same tree or
+// private copies.
+
+type Snapshot struct {
+	root *DJSON
+}
+
+// Snapshot takes a deep copy of m; later changes to m do not show.
+
+func (m *DJSON) Snapshot() *Snapshot {
+	return &Snapshot{root: m.Clone()}
+}
+
+// Clone returns a mutable copy, e.g. for a writer preparing the next
+// version.
+
+func (s *Snapshot) Clone() *DJSON {
+	return s.root.Clone()
+}
+
+func wrapSnapshot(m *DJSON, ok bool) (*Snapshot, bool) {
+	if !ok || m == nil {
+		return nil, false
+	}
+	return &Snapshot{root: m}, true
+}
+
+func (s *Snapshot) Get(key ...interface{}) (*Snapshot, bool) {
+	return wrapSnapshot(s.root.Get(key...))
+}
+
+func (s *Snapshot) GetAsObject(key ...interface{}) (*Snapshot, bool) {
+	return wrapSnapshot(s.root.GetAsObject(key...))
+}
+
+func (s *Snapshot) GetAsArray(key ...interface{}) (*Snapshot, bool) {
+	return wrapSnapshot(s.root.GetAsArray(key...))
+}
+
+// GetAsInterface returns scalars as they are and objects or arrays as
+// copies.
+
+func (s *Snapshot) GetAsInterface(key ...interface{}) interface{} {
+	return cloneElement(s.root.GetAsInterface(key...))
+}
+
+func (s *Snapshot) GetAsInt(key ...interface{}) int64 {
+	return s.root.GetAsInt(key...)
+}
+
+func (s *Snapshot) GetAsBool(key ...interface{}) bool {
+	return s.root.GetAsBool(key...)
+}
+
+func (s *Snapshot) GetAsFloat(key ...interface{}) float64 {
+	return s.root.GetAsFloat(key...)
+}
+
+func (s *Snapshot) GetAsString(key ...interface{}) string {
+	return s.root.GetAsString(key...)
+}
+
+func (s *Snapshot) GetAsBigInt(key ...interface{}) *big.Int {
+	return s.root.GetAsBigInt(key...)
+}
+
+func (s *Snapshot) GetAsBigFloat(key ...interface{}) *big.Float {
+	return s.root.GetAsBigFloat(key...)
+}
+
+func (s *Snapshot) GetAsDecimalString(key ...interface{}) (string, bool) {
+	return s.root.GetAsDecimalString(key...)
+}
+
+// lookupPath resolves path as the *Path accessors of DJSON do and hands
+// the container holding the last token to one of the funcs. Unlike
+// DoPathFunc it never pads an array to reach an index, so it leaves the
+// shared tree untouched; false means the path does not resolve.
+
+func (s *Snapshot) lookupPath(path string,
+	arrayTaskFunc func(da *DA, idx int),
+	objectTaskFunc func(do *DO, key string)) bool {
+
+	tokens := PathTokenizer(path)
+	if len(tokens) == 0 {
+		return false
+	}
+
+	parent, failed := getPathElement(s.root.GetAsInterface(), tokens[:len(tokens)-1])
+	if failed >= 0 {
+		return false
+	}
+
+	switch tkey := tokens[len(tokens)-1].(type) {
+	case string:
+		obj, ok := parent.(*DO)
+		if !ok {
+			return false
+		}
+		objectTaskFunc(obj, tkey)
+	case int:
+		arr, ok := parent.(*DA)
+		if !ok || tkey < 0 || tkey >= arr.Size() {
+			return false
+		}
+		arrayTaskFunc(arr, tkey)
+	default:
+		return false
+	}
+
+	return true
+}
+
+func (s *Snapshot) GetAsObjectPath(path string) (*Snapshot, bool) {
+	var obj *DO
+
+	s.lookupPath(path,
+		func(da *DA, idx int) {
+			obj, _ = da.GetAsObject(idx)
+		},
+		func(do *DO, key string) {
+			obj, _ = do.GetAsObject(key)
+		},
+	)
+
+	if obj == nil {
+		return nil, false
+	}
+	return wrapSnapshot(elementToDJSON(obj))
+}
+
+func (s *Snapshot) GetAsArrayPath(path string) (*Snapshot, bool) {
+	var arr *DA
+
+	s.lookupPath(path,
+		func(da *DA, idx int) {
+			arr, _ = da.GetAsArray(idx)
+		},
+		func(do *DO, key string) {
+			arr, _ = do.GetAsArray(key)
+		},
+	)
+
+	if arr == nil {
+		return nil, false
+	}
+	return wrapSnapshot(elementToDJSON(arr))
+}
+
+func (s *Snapshot) GetAsFloatPath(path string, defFloat ...float64) float64 {
+	var retFloat float64
+	var ok bool
+
+	found := s.lookupPath(path,
+		func(da *DA, idx int) {
+			retFloat, ok = da.GetAsFloat(idx)
+		},
+		func(do *DO, key string) {
+			retFloat, ok = do.GetAsFloat(key)
+		},
+	)
+
+	if found && ok {
+		return retFloat
+	}
+
+	if len(defFloat) > 0 {
+		return defFloat[0]
+	}
+
+	return 0
+}
+
+func (s *Snapshot) GetAsIntPath(path string, defInt ...int64) int64 {
+	var retInt int64
+	var ok bool
+
+	found := s.lookupPath(path,
+		func(da *DA, idx int) {
+			retInt, ok = da.GetAsInt(idx)
+		},
+		func(do *DO, key string) {
+			retInt, ok = do.GetAsInt(key)
+		},
+	)
+
+	if found && ok {
+		return retInt
+	}
+
+	if len(defInt) > 0 {
+		return defInt[0]
+	}
+
+	return 0
+}
+
+func (s *Snapshot) GetAsBoolPath(path string, defBool ...bool) bool {
+	var retBool bool
+	var ok bool
+
+	found := s.lookupPath(path,
+		func(da *DA, idx int) {
+			retBool, ok = da.GetAsBool(idx)
+		},
+		func(do *DO, key string) {
+			retBool, ok = do.GetAsBool(key)
+		},
+	)
+
+	if found && ok {
+		return retBool
+	}
+
+	if len(defBool) > 0 {
+		return defBool[0]
+	}
+
+	return false
+}
+
+func (s *Snapshot) GetAsStringPath(path string) string {
+	var retStr string
+
+	s.lookupPath(path,
+		func(da *DA, idx int) {
+			retStr = da.GetAsString(idx)
+		},
+		func(do *DO, key string) {
+			retStr = do.GetAsString(key)
+		},
+	)
+
+	return retStr
+}
+
+func (s *Snapshot) GetTypePath(path string) string {
+	var pathType string
+
+	s.lookupPath(path,
+		func(da *DA, idx int) {
+			pathType, _ = da.GetType(idx)
+		},
+		func(do *DO, key string) {
+			pathType, _ = do.GetType(key)
+		},
+	)
+
+	return pathType
+}
+
+func (s *Snapshot) GetKeysPath(path string) ([]string, error) {
+	rk := make([]string, 0)
+
+	found := s.lookupPath(path,
+		func(da *DA, idx int) {
+			if ddo, ok := da.GetAsObject(idx); ok {
+				rk = append(rk, ddo.Keys()...)
+			}
+		},
+		func(do *DO, key string) {
+			if ddo, ok := do.GetAsObject(key); ok {
+				rk = append(rk, ddo.Keys()...)
+			}
+		},
+	)
+
+	if !found {
+		return []string{}, invalidPathError
+	}
+
+	return rk, nil
+}
+
+func (s *Snapshot) GetPointer(pointer string) (*Snapshot, bool) {
+	return wrapSnapshot(s.root.GetPointer(pointer))
+}
+
+func (s *Snapshot) HasPointer(pointer string) bool {
+	return s.root.HasPointer(pointer)
+}
+
+// Query returns a copy of the matched values.
+
+func (s *Snapshot) Query(expr string) (*DJSON, error) {
+	ret, err := s.root.Query(expr)
+	if err != nil {
+		return nil, err
+	}
+	return ret.Clone(), nil
+}
+
+func (s *Snapshot) QueryPaths(expr string) ([]string, error) {
+	return s.root.QueryPaths(expr)
+}
+
+func (s *Snapshot) IsBool(key ...interface{}) bool {
+	return s.root.IsBool(key...)
+}
+
+func (s *Snapshot) IsInt(key ...interface{}) bool {
+	return s.root.IsInt(key...)
+}
+
+func (s *Snapshot) IsNumeric(key ...interface{}) bool {
+	return s.root.IsNumeric(key...)
+}
+
+func (s *Snapshot) IsFloat(key ...interface{}) bool {
+	return s.root.IsFloat(key...)
+}
+
+func (s *Snapshot) IsString(key ...interface{}) bool {
+	return s.root.IsString(key...)
+}
+
+func (s *Snapshot) IsNull(key ...interface{}) bool {
+	return s.root.IsNull(key...)
+}
+
+func (s *Snapshot) IsObject(key ...interface{}) bool {
+	return s.root.IsObject(key...)
+}
+
+func (s *Snapshot) IsArray(key ...interface{}) bool {
+	return s.root.IsArray(key...)
+}
+
+func (s *Snapshot) GetType(key ...interface{}) string {
+	return s.root.GetType(key...)
+}
+
+func (s *Snapshot) Size() int {
+	return s.root.Size()
+}
+
+func (s *Snapshot) Length() int {
+	return s.root.Length()
+}
+
+func (s *Snapshot) HasKey(key interface{}) bool {
+	return s.root.HasKey(key)
+}
+
+func (s *Snapshot) HasKeys(k ...interface{}) bool {
+	return s.root.HasKeys(k...)
+}
+
+func (s *Snapshot) GetKeys(k ...interface{}) []string {
+	return s.root.GetKeys(k...)
+}
+
+func (s *Snapshot) Equal(t *DJSON) bool {
+	return s.root.Equal(t)
+}
+
+func (s *Snapshot) ToString() string {
+	return s.root.ToString()
+}
+
+func (s *Snapshot) ToCanonical() ([]byte, error) {
+	return s.root.ToCanonical()
+}
+
+// Unmarshal works on a copy, since *DJSON, *DO and *DA fields of v would
+// otherwise share the snapshot's tree.
+
+func (s *Snapshot) Unmarshal(v interface{}) error {
+	return Unmarshal(s.root.Clone(), v)
+}
+
+// SnapshotStore holds the current version of a shared document. Readers
+// Load the current snapshot without locking; writers Store or Update a
+// new version, which is swapped in atomically.
+
+type SnapshotStore struct {
+	mu      sync.Mutex
+	current atomic.Pointer[Snapshot]
+}
+
+func NewSnapshotStore(doc *DJSON) *SnapshotStore {
+	s := &SnapshotStore{}
+	s.current.Store(doc.Snapshot())
+	return s
+}
+
+func (s *SnapshotStore) Load() *Snapshot {
+	return s.current.Load()
+}
+
+// Store replaces the current version with a snapshot of doc.
+
+func (s *SnapshotStore) Store(doc *DJSON) {
+	snap := doc.Snapshot()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.current.Store(snap)
+}
+
+// Update hands a mutable copy of the current version to fn and swaps it in
+// if fn returns nil. Updates are serialized, so none is lost. fn must not
+// keep doc after it returns.
+
+func (s *SnapshotStore) Update(fn func(doc *DJSON) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := s.current.Load().Clone()
+	if err := fn(next); err != nil {
+		return err
+	}
+
+	s.current.Store(&Snapshot{root: next})
+	return nil
+}
@@ -0,0 +1,116 @@
+package djson
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	aJson := NewDJSON().Parse(`{"name":"gateway","upstreams":[{"host":"a"},{"host":"b"}]}`)
+
+	snap := aJson.Snapshot()
+	aJson.Put("name", "changed")
+	aJson.UpdatePath(`["upstreams"][0]["host"]`, "z")
+
+	if snap.GetAsString("name") != "gateway" || snap.GetAsStringPath(`["upstreams"][0]["host"]`) != "a" {
+		t.Fatal(snap.ToString())
+	}
+
+	ups, ok := snap.GetAsArray("upstreams")
+	if !ok || ups.Length() != 2 {
+		t.Fatal(ups)
+	}
+
+	copied := snap.Clone()
+	copied.UpdatePath(`["upstreams"][1]["host"]`, "y")
+	if snap.GetAsStringPath(`["upstreams"][1]["host"]`) != "b" {
+		t.Fatal(snap.ToString())
+	}
+
+	if obj, ok := snap.GetAsInterface("upstreams").(*DA); !ok || obj == ups.root.Array {
+		t.Fatal("expected a private copy")
+	}
+}
+
+func TestSnapshotPathReadOnly(t *testing.T) {
+	snap := NewDJSON().Parse(`{"a":[1],"b":{"c":[{"d":true}]}}`).Snapshot()
+
+	var wg sync.WaitGroup
+	for r := 0; r < 8; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if snap.GetAsIntPath(`["a"][3]`, -1) != -1 || snap.GetAsStringPath(`["b"]["c"][5]`) != "" {
+					t.Error(snap.ToString())
+					return
+				}
+				if _, ok := snap.GetAsObjectPath(`["b"]["c"][2]`); ok {
+					t.Error(snap.ToString())
+					return
+				}
+				if _, err := snap.GetKeysPath(`["a"][9]`); err == nil {
+					t.Error(snap.ToString())
+					return
+				}
+				if !snap.GetAsBoolPath(`["b"]["c"][0]["d"]`) || snap.GetTypePath(`["a"][0]`) != "int" {
+					t.Error(snap.ToString())
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if snap.ToString() != `{"a":[1],"b":{"c":[{"d":true}]}}` {
+		t.Fatal(snap.ToString())
+	}
+}
+
+func TestSnapshotStore(t *testing.T) {
+	store := NewSnapshotStore(NewDJSON().Parse(`{"version":0,"items":[]}`))
+
+	var wg sync.WaitGroup
+	for r := 0; r < 8; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				snap := store.Load()
+				items, _ := snap.GetAsArrayPath(`["items"]`)
+				if int64(items.Length()) != snap.GetAsIntPath(`["version"]`) {
+					t.Error(snap.ToString())
+					return
+				}
+			}
+		}()
+	}
+
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				_ = store.Update(func(doc *DJSON) error {
+					doc.Put("version", doc.GetAsInt("version")+1)
+					return doc.PushBackPath(`["items"]`, i)
+				})
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if store.Load().GetAsInt("version") != 100 {
+		t.Fatal(store.Load().ToString())
+	}
+
+	errStop := errors.New("stop")
+	if err := store.Update(func(doc *DJSON) error {
+		doc.Put("version", -1)
+		return errStop
+	}); err != errStop || store.Load().GetAsInt("version") != 100 {
+		t.Fatal(err, store.Load().ToString())
+	}
+}
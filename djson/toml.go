@@ -0,0 +1,870 @@
+package djson
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// TOML (v1.0.0) documents load into ordered objects. Dates and times have
+// no JSON type and are kept as strings as written. Integers must fit in
+// an int64, as the specification requires.
+
+// tomlMaxDepth bounds the nesting of inline tables and arrays, as
+// yamlMaxDepth does for YAML.
+
+const tomlMaxDepth = 1000
+
+var (
+	tomlBareKeyRegExp  = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	tomlDecIntRegExp   = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)$`)
+	tomlHexIntRegExp   = regexp.MustCompile(`^0x[0-9A-Fa-f](_?[0-9A-Fa-f])*$`)
+	tomlOctIntRegExp   = regexp.MustCompile(`^0o[0-7](_?[0-7])*$`)
+	tomlBinIntRegExp   = regexp.MustCompile(`^0b[01](_?[01])*$`)
+	tomlFloatRegExp    = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)(\.[0-9](_?[0-9])*)?([eE][+-]?[0-9](_?[0-9])*)?$`)
+	tomlDateTimeRegExp = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}([Tt ][0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?([Zz]|[+-][0-9]{2}:[0-9]{2})?)?$`)
+	tomlTimeRegExp     = regexp.MustCompile(`^[0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?$`)
+)
+
+type tomlParser struct {
+	doc     string
+	pos     int
+	root    *DO
+	current *DO
+
+	// tables opened by a [header], tables that are inline and closed to
+	// extension, tables created by dotted keys, and arrays made by [[header]]
+	headers     map[*DO]bool
+	inlines     map[*DO]bool
+	dotted      map[*DO]bool
+	arrayTables map[*DA]bool
+
+	// nesting of the inline tables and arrays being parsed
+	depth int
+}
+
+// ParseTOML parses a TOML document. On error m is left unchanged and the
+// error is a *ParseError carrying the line.
+
+func (m *DJSON) ParseTOML(doc string) (*DJSON, error) {
+	p := &tomlParser{
+		doc:         doc,
+		root:        NewOrderedObject(),
+		headers:     make(map[*DO]bool),
+		inlines:     make(map[*DO]bool),
+		dotted:      make(map[*DO]bool),
+		arrayTables: make(map[*DA]bool),
+	}
+	p.current = p.root
+
+	if err := p.parse(); err != nil {
+		return m, err
+	}
+
+	ret, _ := elementToDJSON(p.root)
+	*m = *ret
+	return m, nil
+}
+
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	return newParseErrorAt(p.doc, int64(p.pos), InvalidSyntaxError, fmt.Sprintf(format, args...))
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.doc)
+}
+
+func (p *tomlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.doc[p.pos]
+}
+
+func (p *tomlParser) skipSpace() {
+	for !p.eof() && (p.doc[p.pos] == ' ' || p.doc[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *tomlParser) skipComment() error {
+	if p.peek() != '#' {
+		return nil
+	}
+
+	for !p.eof() && p.doc[p.pos] != '\n' {
+		c := p.doc[p.pos]
+		if (c < 0x20 && c != '\t' && !(c == '\r' && p.pos+1 < len(p.doc) && p.doc[p.pos+1] == '\n')) || c == 0x7f {
+			return p.errorf("control character in comment")
+		}
+		p.pos++
+	}
+	return nil
+}
+
+// skipBlank skips whitespace, comments and newlines.
+
+func (p *tomlParser) skipBlank() error {
+	for {
+		p.skipSpace()
+		if err := p.skipComment(); err != nil {
+			return err
+		}
+		if !p.newline() {
+			return nil
+		}
+	}
+}
+
+func (p *tomlParser) newline() bool {
+	if strings.HasPrefix(p.doc[p.pos:], "\n") {
+		p.pos++
+		return true
+	}
+	if strings.HasPrefix(p.doc[p.pos:], "\r\n") {
+		p.pos += 2
+		return true
+	}
+	return false
+}
+
+// endOfLine requires the rest of the line to be blank or a comment.
+
+func (p *tomlParser) endOfLine() error {
+	p.skipSpace()
+	if err := p.skipComment(); err != nil {
+		return err
+	}
+	if p.eof() || p.newline() {
+		return nil
+	}
+	return p.errorf("expected end of line, found %q", p.peek())
+}
+
+func (p *tomlParser) parse() error {
+	for {
+		if err := p.skipBlank(); err != nil {
+			return err
+		}
+		if p.eof() {
+			return nil
+		}
+
+		var err error
+		if p.peek() == '[' {
+			err = p.parseHeader()
+		} else {
+			err = p.parseKeyValue(p.current)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := p.endOfLine(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *tomlParser) parseKey() ([]string, error) {
+	keys := make([]string, 0, 1)
+
+	for {
+		p.skipSpace()
+
+		var key string
+		switch p.peek() {
+		case '"':
+			s, err := p.parseBasicString()
+			if err != nil {
+				return nil, err
+			}
+			key = s
+		case '\'':
+			s, err := p.parseLiteralString()
+			if err != nil {
+				return nil, err
+			}
+			key = s
+		default:
+			start := p.pos
+			for !p.eof() {
+				c := p.doc[p.pos]
+				if !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+					break
+				}
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, p.errorf("invalid key")
+			}
+			key = p.doc[start:p.pos]
+		}
+
+		keys = append(keys, key)
+
+		p.skipSpace()
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.pos++
+	}
+}
+
+// descend returns the table under key in parent, creating it if missing.
+// Arrays of tables resolve to their last element.
+
+func (p *tomlParser) descend(parent *DO, key string, byDottedKey bool) (*DO, error) {
+	v, ok := parent.Map[key]
+	if !ok {
+		obj := NewOrderedObject()
+		if byDottedKey {
+			p.dotted[obj] = true
+		}
+		parent.Put(key, obj)
+		return obj, nil
+	}
+
+	switch t := v.(type) {
+	case *DO:
+		if p.inlines[t] {
+			return nil, p.errorf("cannot extend inline table %q", key)
+		}
+		if byDottedKey && p.headers[t] {
+			return nil, p.errorf("cannot extend table %q with dotted keys", key)
+		}
+		return t, nil
+	case *DA:
+		if !byDottedKey && p.arrayTables[t] && t.Size() > 0 {
+			if last, ok := t.Element[t.Size()-1].(*DO); ok {
+				return last, nil
+			}
+		}
+	}
+
+	return nil, p.errorf("key %q is already defined as a value", key)
+}
+
+func (p *tomlParser) parseHeader() error {
+	isArray := strings.HasPrefix(p.doc[p.pos:], "[[")
+	if isArray {
+		p.pos += 2
+	} else {
+		p.pos++
+	}
+
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+
+	closing := "]"
+	if isArray {
+		closing = "]]"
+	}
+	if !strings.HasPrefix(p.doc[p.pos:], closing) {
+		return p.errorf("expected %s", closing)
+	}
+	p.pos += len(closing)
+
+	table := p.root
+	for _, key := range keys[:len(keys)-1] {
+		if table, err = p.descend(table, key, false); err != nil {
+			return err
+		}
+	}
+
+	last := keys[len(keys)-1]
+	v, exists := table.Map[last]
+
+	if isArray {
+		arr, ok := v.(*DA)
+		if !exists {
+			arr = NewArray()
+			p.arrayTables[arr] = true
+			table.Put(last, arr)
+		} else if !ok || !p.arrayTables[arr] {
+			return p.errorf("key %q is not an array of tables", last)
+		}
+
+		p.current = NewOrderedObject()
+		arr.Element = append(arr.Element, p.current)
+		return nil
+	}
+
+	if exists {
+		obj, ok := v.(*DO)
+		if !ok || p.headers[obj] || p.inlines[obj] || p.dotted[obj] {
+			return p.errorf("table %q is already defined", last)
+		}
+		p.headers[obj] = true
+		p.current = obj
+		return nil
+	}
+
+	p.current = NewOrderedObject()
+	p.headers[p.current] = true
+	table.Put(last, p.current)
+	return nil
+}
+
+func (p *tomlParser) parseKeyValue(table *DO) error {
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+
+	if p.peek() != '=' {
+		return p.errorf("expected = after key")
+	}
+	p.pos++
+	p.skipSpace()
+
+	for _, key := range keys[:len(keys)-1] {
+		if table, err = p.descend(table, key, true); err != nil {
+			return err
+		}
+	}
+
+	last := keys[len(keys)-1]
+	if table.HasKey(last) {
+		return p.errorf("key %q is already defined", last)
+	}
+
+	v, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+
+	table.set(last, v)
+	return nil
+}
+
+func (p *tomlParser) parseValue() (interface{}, error) {
+	switch c := p.peek(); {
+	case c == '"':
+		return p.parseBasicString()
+	case c == '\'':
+		return p.parseLiteralString()
+	case c == '[' || c == '{':
+		if p.depth >= tomlMaxDepth {
+			return nil, p.errorf("nesting too deep")
+		}
+		p.depth++
+		defer func() { p.depth-- }()
+
+		if c == '[' {
+			return p.parseArray()
+		}
+		return p.parseInlineTable()
+	case strings.HasPrefix(p.doc[p.pos:], "true"):
+		p.pos += 4
+		return true, nil
+	case strings.HasPrefix(p.doc[p.pos:], "false"):
+		p.pos += 5
+		return false, nil
+	}
+
+	return p.parseScalarToken()
+}
+
+func (p *tomlParser) parseScalarToken() (interface{}, error) {
+	start := p.pos
+	for !p.eof() {
+		c := p.doc[p.pos]
+		if c >= '0' && c <= '9' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || strings.IndexByte("+-_.:", c) >= 0 {
+			p.pos++
+			continue
+		}
+		// a space may separate the date and time of a datetime
+		if c == ' ' && p.pos-start == 10 && tomlDateTimeRegExp.MatchString(p.doc[start:p.pos]) &&
+			p.pos+3 < len(p.doc) && p.doc[p.pos+3] == ':' {
+			p.pos++
+			continue
+		}
+		break
+	}
+
+	token := p.doc[start:p.pos]
+	if token == "" {
+		return nil, p.errorf("expected a value")
+	}
+
+	switch {
+	case tomlDateTimeRegExp.MatchString(token) || tomlTimeRegExp.MatchString(token):
+		return token, nil
+	case strings.Contains(token, "inf") || strings.Contains(token, "nan"):
+		p.pos = start
+		return nil, p.errorf("%s has no JSON representation", token)
+	case tomlDecIntRegExp.MatchString(token):
+		digits := strings.ReplaceAll(token, "_", "")
+		i, err := strconv.ParseInt(digits, 10, 64)
+		if err != nil {
+			p.pos = start
+			return nil, p.errorf("integer %s out of range", token)
+		}
+		return i, nil
+	case tomlHexIntRegExp.MatchString(token), tomlOctIntRegExp.MatchString(token), tomlBinIntRegExp.MatchString(token):
+		i, err := strconv.ParseInt(token, 0, 64)
+		if err != nil {
+			p.pos = start
+			return nil, p.errorf("integer %s out of range", token)
+		}
+		return i, nil
+	case tomlFloatRegExp.MatchString(token):
+		f, err := strconv.ParseFloat(strings.ReplaceAll(token, "_", ""), 64)
+		if err != nil || math.IsInf(f, 0) {
+			p.pos = start
+			return nil, p.errorf("float %s out of range", token)
+		}
+		return f, nil
+	}
+
+	p.pos = start
+	return nil, p.errorf("invalid value %q", token)
+}
+
+func (p *tomlParser) parseArray() (interface{}, error) {
+	p.pos++
+	arr := NewArray()
+
+	for {
+		if err := p.skipBlank(); err != nil {
+			return nil, err
+		}
+
+		if p.peek() == ']' {
+			p.pos++
+			return arr, nil
+		}
+
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		arr.Element = append(arr.Element, v)
+
+		if err := p.skipBlank(); err != nil {
+			return nil, err
+		}
+
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return arr, nil
+		default:
+			return nil, p.errorf("expected , or ] in array")
+		}
+	}
+}
+
+func (p *tomlParser) parseInlineTable() (interface{}, error) {
+	p.pos++
+	obj := NewOrderedObject()
+
+	p.skipSpace()
+	if p.peek() == '}' {
+		p.pos++
+		p.inlines[obj] = true
+		return obj, nil
+	}
+
+	for {
+		if err := p.parseKeyValue(obj); err != nil {
+			return nil, err
+		}
+
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			p.freeze(obj)
+			return obj, nil
+		default:
+			return nil, p.errorf("expected , or } in inline table")
+		}
+	}
+}
+
+// freeze marks an inline table and the tables its dotted keys created as
+// closed to extension.
+
+func (p *tomlParser) freeze(obj *DO) {
+	p.inlines[obj] = true
+	for _, v := range obj.Map {
+		if sub, ok := v.(*DO); ok && p.dotted[sub] {
+			p.freeze(sub)
+		}
+	}
+}
+
+func (p *tomlParser) parseEscape(sb *strings.Builder) error {
+	p.pos++
+	if p.eof() {
+		return p.errorf("unterminated escape")
+	}
+
+	c := p.doc[p.pos]
+	p.pos++
+
+	switch c {
+	case 'b':
+		sb.WriteByte('\b')
+	case 't':
+		sb.WriteByte('\t')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'r':
+		sb.WriteByte('\r')
+	case '"':
+		sb.WriteByte('"')
+	case '\\':
+		sb.WriteByte('\\')
+	case 'u', 'U':
+		size := 4
+		if c == 'U' {
+			size = 8
+		}
+		if p.pos+size > len(p.doc) {
+			return p.errorf("short unicode escape")
+		}
+		code, err := strconv.ParseUint(p.doc[p.pos:p.pos+size], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return p.errorf("invalid unicode escape")
+		}
+		p.pos += size
+		sb.WriteRune(rune(code))
+	default:
+		p.pos -= 2
+		return p.errorf("invalid escape \\%c", c)
+	}
+
+	return nil
+}
+
+func (p *tomlParser) checkStringChar(c byte, multiline bool) error {
+	if c == '\t' || (multiline && c == '\n') || (multiline && c == '\r' && strings.HasPrefix(p.doc[p.pos:], "\r\n")) {
+		return nil
+	}
+	if c < 0x20 || c == 0x7f {
+		return p.errorf("control character in string")
+	}
+	return nil
+}
+
+func (p *tomlParser) parseBasicString() (string, error) {
+	if strings.HasPrefix(p.doc[p.pos:], `"""`) {
+		return p.parseMultilineBasicString()
+	}
+
+	p.pos++
+	var sb strings.Builder
+
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", p.errorf("unterminated string")
+		}
+
+		c := p.doc[p.pos]
+		switch c {
+		case '"':
+			p.pos++
+			return sb.String(), nil
+		case '\\':
+			if err := p.parseEscape(&sb); err != nil {
+				return "", err
+			}
+		default:
+			if err := p.checkStringChar(c, false); err != nil {
+				return "", err
+			}
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+func (p *tomlParser) parseMultilineBasicString() (string, error) {
+	p.pos += 3
+	p.newline()
+
+	var sb strings.Builder
+
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated multi-line string")
+		}
+
+		if strings.HasPrefix(p.doc[p.pos:], `"""`) {
+			// up to two quotes may sit right before the closing delimiter
+			n := 3
+			for n < 5 && p.pos+n < len(p.doc) && p.doc[p.pos+n] == '"' {
+				n++
+			}
+			sb.WriteString(strings.Repeat(`"`, n-3))
+			p.pos += n
+			return sb.String(), nil
+		}
+
+		c := p.doc[p.pos]
+		if c == '\\' {
+			// a line ending backslash trims the newline and following blanks
+			rest := strings.TrimLeft(p.doc[p.pos+1:], " \t")
+			if strings.HasPrefix(rest, "\n") || strings.HasPrefix(rest, "\r\n") {
+				p.pos = len(p.doc) - len(strings.TrimLeft(rest, " \t\r\n"))
+				continue
+			}
+			if err := p.parseEscape(&sb); err != nil {
+				return "", err
+			}
+			continue
+		}
+
+		if err := p.checkStringChar(c, true); err != nil {
+			return "", err
+		}
+		sb.WriteByte(c)
+		p.pos++
+	}
+}
+
+func (p *tomlParser) parseLiteralString() (string, error) {
+	multiline := strings.HasPrefix(p.doc[p.pos:], "'''")
+	if multiline {
+		p.pos += 3
+		p.newline()
+	} else {
+		p.pos++
+	}
+
+	start := p.pos
+	for {
+		if p.eof() || (!multiline && p.peek() == '\n') {
+			return "", p.errorf("unterminated string")
+		}
+
+		c := p.doc[p.pos]
+		if multiline && strings.HasPrefix(p.doc[p.pos:], "'''") {
+			n := 3
+			for n < 5 && p.pos+n < len(p.doc) && p.doc[p.pos+n] == '\'' {
+				n++
+			}
+			s := p.doc[start:p.pos] + strings.Repeat("'", n-3)
+			p.pos += n
+			return s, nil
+		}
+		if !multiline && c == '\'' {
+			s := p.doc[start:p.pos]
+			p.pos++
+			return s, nil
+		}
+
+		if err := p.checkStringChar(c, multiline); err != nil {
+			return "", err
+		}
+		p.pos++
+	}
+}
+
+// ToTOML writes m, which must be an object. TOML has no null and no
+// integers outside int64, so such values are reported as
+// UnsupportedValueError with their key path.
+
+func (m *DJSON) ToTOML() (string, error) {
+	if !m.IsObject() {
+		return "", NotObjectError
+	}
+
+	var sb strings.Builder
+	if err := writeTOMLTable(&sb, nil, m.Object); err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
+
+func tomlKey(key string) string {
+	if tomlBareKeyRegExp.MatchString(key) {
+		return key
+	}
+	return tomlString(key)
+}
+
+func tomlString(s string) string {
+	var sb strings.Builder
+
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\r':
+			sb.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&sb, `\u%04X`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+
+	return sb.String()
+}
+
+func tomlPath(path []string) string {
+	keys := make([]string, len(path))
+	for idx := range path {
+		keys[idx] = tomlKey(path[idx])
+	}
+	return strings.Join(keys, ".")
+}
+
+func isTOMLArrayOfTables(element interface{}) bool {
+	arr, ok := element.(*DA)
+	if !ok || arr.Size() == 0 {
+		return false
+	}
+
+	for idx := range arr.Element {
+		if _, ok := arr.Element[idx].(*DO); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func tomlInlineValue(path []string, element interface{}) (string, error) {
+	switch t := element.(type) {
+	case nil:
+		return "", fmt.Errorf("%s: %w", tomlPath(path), UnsupportedValueError)
+	case bool:
+		return strconv.FormatBool(t), nil
+	case string:
+		return tomlString(t), nil
+	case *DO:
+		parts := make([]string, 0, t.Size())
+		for _, k := range t.Keys() {
+			v, err := tomlInlineValue(append(path, k), t.Map[k])
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, tomlKey(k)+" = "+v)
+		}
+		if len(parts) == 0 {
+			return "{}", nil
+		}
+		return "{ " + strings.Join(parts, ", ") + " }", nil
+	case *DA:
+		parts := make([]string, 0, t.Size())
+		for idx := range t.Element {
+			v, err := tomlInlineValue(append(path, strconv.Itoa(idx)), t.Element[idx])
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, v)
+		}
+		return "[" + strings.Join(parts, ", ") + "]", nil
+	}
+
+	switch elementType(element) {
+	case JSON_INT:
+		b, _ := getBigIntBase(element)
+		if b == nil || !b.IsInt64() {
+			return "", fmt.Errorf("%s: %w", tomlPath(path), UnsupportedValueError)
+		}
+		return b.String(), nil
+	case JSON_FLOAT:
+		if n, ok := element.(json.Number); ok {
+			return string(n), nil
+		}
+		f, _ := getFloatBase(element)
+		s := strconv.FormatFloat(f, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".e") {
+			s += ".0"
+		}
+		return s, nil
+	}
+
+	return "", fmt.Errorf("%s: %w", tomlPath(path), UnsupportedValueError)
+}
+
+func hasTOMLInlineValues(obj *DO) bool {
+	for _, v := range obj.Map {
+		if _, ok := v.(*DO); !ok && !isTOMLArrayOfTables(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeTOMLTable(sb *strings.Builder, path []string, obj *DO) error {
+	deferred := make([]string, 0)
+
+	for _, k := range obj.Keys() {
+		v := obj.Map[k]
+
+		if _, ok := v.(*DO); ok || isTOMLArrayOfTables(v) {
+			deferred = append(deferred, k)
+			continue
+		}
+
+		s, err := tomlInlineValue(append(path, k), v)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(sb, "%s = %s\n", tomlKey(k), s)
+	}
+
+	for _, k := range deferred {
+		sub := append(append([]string{}, path...), k)
+
+		if subObj, ok := obj.Map[k].(*DO); ok {
+			// tables holding only subtables are implied by their headers
+			if subObj.Size() == 0 || hasTOMLInlineValues(subObj) {
+				if sb.Len() > 0 {
+					sb.WriteByte('\n')
+				}
+				fmt.Fprintf(sb, "[%s]\n", tomlPath(sub))
+			}
+			if err := writeTOMLTable(sb, sub, subObj); err != nil {
+				return err
+			}
+			continue
+		}
+
+		arr := obj.Map[k].(*DA)
+		for idx := range arr.Element {
+			if sb.Len() > 0 {
+				sb.WriteByte('\n')
+			}
+			fmt.Fprintf(sb, "[[%s]]\n", tomlPath(sub))
+			if err := writeTOMLTable(sb, sub, arr.Element[idx].(*DO)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+package djson
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const tomlTestDoc = `# service configuration
+name = "gateway"
+port = 8_080
+mask = 0xff
+ratio = 7.5e-1
+started = 1979-05-27 07:32:00Z
+motd = """
+Hello \
+  world"""
+path = 'C:\temp'
+
+[server]
+host = "localhost"
+tls.cert = "server.pem"
+tls.key = "server.key"
+
+[[upstreams]]
+host = "a.internal"
+weight = 1
+
+[[upstreams]]
+host = "b.internal"
+options = { timeout = 5, retry = true }
+
+[limits."per user"]
+rps = [10, 20,
+  30, # burst
+]
+`
+
+func TestParseTOML(t *testing.T) {
+	aJson, err := NewDJSON().ParseTOML(tomlTestDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"name":"gateway","port":8080,"mask":255,"ratio":0.75,"started":"1979-05-27 07:32:00Z","motd":"Hello world","path":"C:\\temp",` +
+		`"server":{"host":"localhost","tls":{"cert":"server.pem","key":"server.key"}},` +
+		`"upstreams":[{"host":"a.internal","weight":1},{"host":"b.internal","options":{"timeout":5,"retry":true}}],` +
+		`"limits":{"per user":{"rps":[10,20,30]}}}`
+
+	if aJson.ToString() != expected {
+		t.Fatalf("expected %s, got %s", expected, aJson.ToString())
+	}
+
+	out, err := aJson.ToTOML()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bJson, err := NewDJSON().ParseTOML(out)
+	if err != nil {
+		t.Fatal(err, out)
+	}
+
+	if bJson.ToString() != expected {
+		t.Fatalf("expected %s, got %s", expected, bJson.ToString())
+	}
+}
+
+func TestParseTOMLError(t *testing.T) {
+	testCases := []struct {
+		doc  string
+		line int
+	}{
+		{"a = 1\na = 2\n", 2},
+		{"[t]\nx = 1\n[t]\n", 3},
+		{"a = 1\nb = { c = 1 }\n[b]\n", 3},
+		{"a = 1\n\nb = \"open\n", 3},
+		{"a = 1 b = 2\n", 1},
+		{"a = 01\n", 1},
+		{"a = inf\n", 1},
+		{"[[a]]\n[a.b]\nx = 1\n[a]\n", 4},
+	}
+
+	for _, tc := range testCases {
+		_, err := NewDJSON().ParseTOML(tc.doc)
+
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Fatalf("%q: expected parse error, got %v", tc.doc, err)
+		}
+
+		if perr.Line != tc.line {
+			t.Fatalf("%q: expected line %d, got %d (%s)", tc.doc, tc.line, perr.Line, perr.Msg)
+		}
+	}
+
+	if _, err := NewDJSON().Parse(`{"a":null}`).ToTOML(); !errors.Is(err, UnsupportedValueError) {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateTOML(t *testing.T) {
+	dv := NewValidator()
+	dv.Compile(`{
+		"type": "OBJECT",
+		"object": {
+			"name": {"type": "STRING", "required": true},
+			"port": {"type": "INT", "min": 1, "max": 65535}
+		}
+	}`)
+
+	aJson, err := NewDJSON().ParseTOML("name = \"gateway\"\nport = 8080\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !dv.IsValid(aJson) {
+		t.Fatal("expected valid config")
+	}
+
+	aJson, _ = NewDJSON().ParseTOML("name = \"gateway\"\nport = 70000\n")
+	if dv.IsValid(aJson) {
+		t.Fatal("expected invalid config")
+	}
+}
+
+// The cases below follow the valid and invalid documents of the
+// toml-test suite (github.com/toml-lang/toml-test) for TOML 1.0.0.
+
+func TestTOMLConformance(t *testing.T) {
+	valid := []struct {
+		doc      string
+		expected string
+	}{
+		{"max = 9223372036854775807\nmin = -9223372036854775808\n", `{"max":9223372036854775807,"min":-9223372036854775808}`},
+		{"a = +99\nb = -17\nc = 0\nd = 1_000\ne = 5_349_221\n", `{"a":99,"b":-17,"c":0,"d":1000,"e":5349221}`},
+		{"hex = 0xDEAD_beef\noct = 0o755\nbin = 0b1101_0110\n", `{"hex":3735928559,"oct":493,"bin":214}`},
+		{"max = 0x7fffffffffffffff\n", `{"max":9223372036854775807}`},
+		{"a = +1.0\nb = 3.1415\nc = -0.01\nd = 5e+22\ne = 1e06\nf = -2E-2\ng = 6.626e-34\nh = 224_617.445_991\n", `{"a":1,"b":3.1415,"c":-0.01,"d":5e+22,"e":1000000,"f":-0.02,"g":6.626e-34,"h":224617.445991}`},
+		{"t = true\nf = false\n", `{"t":true,"f":false}`},
+		{"odt = 1979-05-27T00:32:00.999999-07:00\nldt = 1979-05-27T07:32:00\nld = 1979-05-27\nlt = 00:32:00.999999\n", `{"odt":"1979-05-27T00:32:00.999999-07:00","ldt":"1979-05-27T07:32:00","ld":"1979-05-27","lt":"00:32:00.999999"}`},
+		{"s = \"tab\\tquote\\\" \\u00e9 \\U0001F600\"\n", `{"s":"tab\tquote\" é 😀"}`},
+		{"s = 'raw \\n'\nm = '''\nline1\nline2'''\n", `{"s":"raw \\n","m":"line1\nline2"}`},
+		{"\"quoted key\" = 1\n'literal key' = 2\n\"\" = 3\n", `{"quoted key":1,"literal key":2,"":3}`},
+		{"a.b.c = 1\na.d = 2\n", `{"a":{"b":{"c":1},"d":2}}`},
+		{"a = [ [ 1, 2 ], [\"a\", \"b\"], [] ]\n", `{"a":[[1,2],["a","b"],[]]}`},
+		{"[a.b.c]\nx = 1\n[a]\ny = 2\n", `{"a":{"b":{"c":{"x":1}},"y":2}}`},
+		{"[[fruit]]\nname = \"apple\"\n[fruit.physical]\ncolor = \"red\"\n[[fruit.variety]]\nname = \"red delicious\"\n[[fruit]]\nname = \"banana\"\n", `{"fruit":[{"name":"apple","physical":{"color":"red"},"variety":[{"name":"red delicious"}]},{"name":"banana"}]}`},
+		{"p = { x = 1, y = { z = [1] } }\n", `{"p":{"x":1,"y":{"z":[1]}}}`},
+		{"# only a comment\n\n  \t\n", `{}`},
+	}
+
+	for _, tc := range valid {
+		aJson, err := NewDJSON().ParseTOML(tc.doc)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.doc, err)
+		}
+
+		if aJson.ToString() != tc.expected {
+			t.Fatalf("%q: expected %s, got %s", tc.doc, tc.expected, aJson.ToString())
+		}
+	}
+
+	invalid := []string{
+		"big = 9223372036854775808\n",
+		"small = -9223372036854775809\n",
+		"hex = 0x8000000000000000\n",
+		"oct = 0o1000000000000000000000\n",
+		"bin = 0b10000000000000000000000000000000000000000000000000000000000000000\n",
+		"a = 1__2\n",
+		"a = _1\n",
+		"a = 1_\n",
+		"a = +0x1\n",
+		"a = 0X1\n",
+		"a = 0b2\n",
+		"a = 1.\n",
+		"a = .1\n",
+		"a = 1e\n",
+		"a = nan\n",
+		"a = True\n",
+		"a = \"\\x41\"\n",
+		"a = \"unterminated\n",
+		"a =\n",
+		"= 1\n",
+		"a b = 1\n",
+		"a.b = 1\na = 2\n",
+		"a = { b = 1 }\na.c = 2\n",
+		"a = { b = 1, }\n",
+		"a = [1 2]\n",
+		"[a]\n[a]\n",
+		"[a\nb = 1\n",
+		"a = [1]\n[[a]]\n",
+	}
+
+	for _, doc := range invalid {
+		if _, err := NewDJSON().ParseTOML(doc); err == nil {
+			t.Fatalf("%q: expected an error", doc)
+		}
+	}
+
+	for _, s := range []string{`{"a":9223372036854775808}`, `{"a":[18446744073709551615]}`} {
+		if _, err := NewDJSON().SetLosslessNumbers(true).Parse(s).ToTOML(); !errors.Is(err, UnsupportedValueError) {
+			t.Fatal(s, err)
+		}
+	}
+}
+
+func TestParseTOMLDepth(t *testing.T) {
+	nested := "a = " + strings.Repeat("[", tomlMaxDepth) + strings.Repeat("]", tomlMaxDepth) + "\n"
+	if _, err := NewDJSON().ParseTOML(nested); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, doc := range []string{
+		"a = " + strings.Repeat("[", tomlMaxDepth+1) + strings.Repeat("]", tomlMaxDepth+1) + "\n",
+		"a = " + strings.Repeat("{ b = [", 1000000),
+	} {
+		var perr *ParseError
+		if _, err := NewDJSON().ParseTOML(doc); !errors.As(err, &perr) || perr.Msg != "nesting too deep" {
+			t.Fatal(err)
+		}
+	}
+}
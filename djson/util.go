@@ -1,10 +1,13 @@
 package djson
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -12,9 +15,15 @@ import (
 )
 
 func ConverMapToObject(dmap map[string]interface{}) *DO {
+	keys := make([]string, 0, len(dmap))
+	for k := range dmap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
 	nObj := NewObject()
-	for k, v := range dmap {
-		nObj.Put(k, v)
+	for _, k := range keys {
+		nObj.Put(k, dmap[k])
 	}
 	return nObj
 }
@@ -73,6 +82,10 @@ func getStringBase(v interface{}) (string, bool) {
 		return "nil", true
 	}
 
+	if n, ok := v.(json.Number); ok {
+		return string(n), true
+	}
+
 	if IsInTypes(v, "string", "bool", "float32", "float64") {
 		return fmt.Sprintf("%v", v), true
 	}
@@ -112,6 +125,11 @@ func getBoolBase(v interface{}) (bool, bool) {
 }
 
 func getFloatBase(v interface{}) (float64, bool) {
+	if n, ok := v.(json.Number); ok {
+		floatVal, err := n.Float64()
+		return floatVal, err == nil
+	}
+
 	if floatVal, err := gov.ToFloat(v); err != nil {
 		return 0, false
 	} else {
@@ -120,6 +138,17 @@ func getFloatBase(v interface{}) (float64, bool) {
 }
 
 func getIntBase(v interface{}) (int64, bool) {
+	if n, ok := v.(json.Number); ok {
+		if intVal, err := n.Int64(); err == nil {
+			return intVal, true
+		}
+		floatVal, err := n.Float64()
+		if err != nil || floatVal < math.MinInt64 || floatVal >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(floatVal), true
+	}
+
 	if intVal, err := gov.ToInt(v); err != nil {
 		return 0, false
 	} else {
@@ -165,6 +194,14 @@ func IsInTypes(v interface{}, types ...string) bool {
 }
 
 func ParseToObject(doc string) (*DO, error) {
+	if IsOrderedObjects() || IsLosslessNumbers() {
+		ret, err := NewDecoder(strings.NewReader(doc)).Decode()
+		if err != nil || !ret.IsObject() {
+			return nil, errors.New("not Object")
+		}
+		return ret.Object, nil
+	}
+
 	var data map[string]interface{}
 
 	d := json.NewDecoder(strings.NewReader(doc))
@@ -179,6 +216,14 @@ func ParseToObject(doc string) (*DO, error) {
 }
 
 func ParseToArray(doc string) (*DA, error) {
+	if IsOrderedObjects() || IsLosslessNumbers() {
+		ret, err := NewDecoder(strings.NewReader(doc)).Decode()
+		if err != nil || !ret.IsArray() {
+			return nil, errors.New("not Array")
+		}
+		return ret.Array, nil
+	}
+
 	var data []interface{}
 
 	d := json.NewDecoder(strings.NewReader(doc))
@@ -260,12 +305,18 @@ func ParseArray(data []interface{}) *DA {
 	return arr
 }
 
+// PathTokenizer splits a path such as ["a"]["b"][0] into keys and
+// indexes. Unquoted numeric tokens are indexes, quoted tokens are always
+// keys, so ["2024"] names a key. Inside quotes, a backslash escapes the
+// quote and another backslash.
+
 func PathTokenizer(path string) []interface{} {
 	rstack := NewRuneStack()
 	token := make([]rune, 0)
-	inTokens := make([]string, 0)
+	outTokens := make([]interface{}, 0)
 
 	prev := rune(0)
+	escaped := false
 	var depthL int
 
 	for _, each := range path {
@@ -283,7 +334,11 @@ func PathTokenizer(path string) []interface{} {
 		} else if depthL == 1 {
 			if peek == '[' && each == ']' && prev != '\\' {
 				if len(token) > 0 {
-					inTokens = append(inTokens, string(token))
+					if intVal, err := strconv.Atoi(string(token)); err == nil {
+						outTokens = append(outTokens, intVal)
+					} else {
+						outTokens = append(outTokens, string(token))
+					}
 					token = make([]rune, 0)
 				}
 				rstack.Pop()
@@ -296,11 +351,17 @@ func PathTokenizer(path string) []interface{} {
 			}
 		} else if depthL == 2 {
 
-			if (peek == '"' && each == '"' && prev != '\\') || (peek == '\'' && each == '\'' && prev != '\\') {
-				if len(token) > 0 {
-					inTokens = append(inTokens, string(token))
-					token = make([]rune, 0)
+			if escaped {
+				if each != peek && each != '\\' {
+					token = append(token, '\\')
 				}
+				token = append(token, each)
+				escaped = false
+			} else if each == '\\' {
+				escaped = true
+			} else if each == peek {
+				outTokens = append(outTokens, string(token))
+				token = make([]rune, 0)
 				rstack.Pop()
 				depthL = 1
 			} else {
@@ -311,14 +372,85 @@ func PathTokenizer(path string) []interface{} {
 		prev = each
 	}
 
-	outTokens := make([]interface{}, 0)
-	for idx := range inTokens {
-		if intVal, err := strconv.Atoi(inTokens[idx]); err == nil {
-			outTokens = append(outTokens, intVal)
-		} else {
-			outTokens = append(outTokens, inTokens[idx])
+	return outTokens
+}
+
+// writeElement serializes like encoding/json, except that object keys are
+// written in the order given by DO.Keys.
+
+func writeElement(buf *bytes.Buffer, element interface{}) error {
+	switch t := element.(type) {
+	case DO:
+		return writeElement(buf, &t)
+	case DA:
+		return writeElement(buf, &t)
+	case *DJSON:
+		return writeElement(buf, t.GetAsInterface())
+	case *DO:
+		if t == nil {
+			buf.WriteString("null")
+			return nil
+		}
+
+		buf.WriteByte('{')
+		for idx, k := range t.Keys() {
+			if idx > 0 {
+				buf.WriteByte(',')
+			}
+
+			key, _ := json.Marshal(k)
+			buf.Write(key)
+			buf.WriteByte(':')
+
+			if err := writeElement(buf, t.Map[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case *DA:
+		if t == nil {
+			buf.WriteString("null")
+			return nil
+		}
+
+		buf.WriteByte('[')
+		for idx := range t.Element {
+			if idx > 0 {
+				buf.WriteByte(',')
+			}
+
+			if err := writeElement(buf, t.Element[idx]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		jsonByte, err := json.Marshal(element)
+		if err != nil {
+			return err
 		}
+		buf.Write(jsonByte)
 	}
 
-	return outTokens
+	return nil
+}
+
+func encodeElement(element interface{}) string {
+	var buf bytes.Buffer
+	if err := writeElement(&buf, element); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+func encodeElementIndent(element interface{}) string {
+	var buf, out bytes.Buffer
+	if err := writeElement(&buf, element); err != nil {
+		return ""
+	}
+
+	if err := json.Indent(&out, buf.Bytes(), "", "   "); err != nil {
+		return ""
+	}
+	return out.String()
 }
@@ -9,6 +9,11 @@ func TestTokenizer(t *testing.T) {
 
 	log.Println(PathTokenizer(`["aa"][1][b_b]`))  // [aa 1 b_b]
 	log.Println(PathTokenizer(`["a'a"][1][b]b]`)) // [a'a 1 b]
+
+	tokens := PathTokenizer(`["a.b"][0]["c"]`)
+	if len(tokens) != 3 || tokens[0] != "a.b" || tokens[1] != 0 || tokens[2] != "c" {
+		t.Fatal(tokens)
+	}
 }
 
 func TestParse(t *testing.T) {
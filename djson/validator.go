@@ -2,6 +2,7 @@ package djson
 
 import (
 	"encoding/base64"
+	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
@@ -180,11 +181,19 @@ type VItem struct {
 	SubItems  []*VItem
 	CheckFunc func(string, ...int64) bool
 	RegExp    *regexp.Regexp
+	TypeName  string
+
+	CheckFloatFunc func(string, ...float64) bool
+
+	constraints *vconstraints
+	values      *vvalues
+	malformed   bool
 }
 
 type Validator struct {
 	Syntax    *DJSON
 	RootItems []*VItem
+	Schema    *Schema
 }
 
 func NewValidator() *Validator {
@@ -194,6 +203,7 @@ func NewValidator() *Validator {
 }
 
 func (m *Validator) Compile(syntax string) bool {
+	m.Schema = nil
 	m.Syntax.Parse(syntax)
 
 	if !m.Syntax.IsObject() && !m.Syntax.IsString() && !m.Syntax.IsArray() {
@@ -219,9 +229,32 @@ func (m *Validator) Compile(syntax string) bool {
 		}
 	}
 
+	for _, vi := range m.RootItems {
+		if vi.isMalformed() {
+			m.Syntax = NewDJSON()
+			m.RootItems = nil
+			return false
+		}
+	}
+
 	return true
 }
 
+// isMalformed reports whether vi or one of its sub items has a rule that
+// could not be compiled.
+
+func (vi *VItem) isMalformed() bool {
+	if vi.malformed {
+		return true
+	}
+	for _, svi := range vi.SubItems {
+		if svi.isMalformed() {
+			return true
+		}
+	}
+	return false
+}
+
 func GetVItem(name string, ejson *DJSON) *VItem {
 	eitem := new(VItem)
 	eitem.Name = name
@@ -299,6 +332,7 @@ func GetVItem(name string, ejson *DJSON) *VItem {
 
 		etype = ejson.GetAsString("type")
 		eitem.IsRequred = ejson.GetAsBool("required")
+		eitem.values = getVValues(ejson)
 		if ejson.GetAsString("regexp") != "" {
 			eitem.RegExp, _ = regexp.Compile(ejson.GetAsString("regexp"))
 		}
@@ -354,6 +388,13 @@ func GetVItem(name string, ejson *DJSON) *VItem {
 
 				}
 			}
+
+			eitem.constraints, ok = getVConstraints(ejson)
+			if !ok {
+				eitem.malformed = true
+			} else if eitem.constraints != nil {
+				eitem.Type = V_TYPE_OBJECT
+			}
 		case "NONEMPTY.STRING":
 			eitem.Type = V_TYPE_STRING
 			if ejson.IsInt("size") {
@@ -525,10 +566,41 @@ func GetVItem(name string, ejson *DJSON) *VItem {
 		eitem.CheckFunc = CheckHexIfExist
 	}
 
+	if vt, ok := lookupType(etype); ok {
+		vt.apply(eitem, ejson)
+	}
+
+	eitem.TypeName = etype
+
 	return eitem
 }
 
+// CompileSchema makes the validator check JSON Schema instead of the
+// Validator syntax. The schema is compiled by the package-level
+// CompileSchema; a compile error leaves the validator unchanged.
+
+func (m *Validator) CompileSchema(schema string, opts ...*SchemaOptions) error {
+	doc := NewDJSON().Parse(schema)
+	if doc.IsNull() {
+		return fmt.Errorf("schema is not an object or a boolean: %w", InvalidSchemaError)
+	}
+
+	compiled, err := CompileSchema(doc, opts...)
+	if err != nil {
+		return err
+	}
+
+	m.Syntax = doc
+	m.RootItems = nil
+	m.Schema = compiled
+	return nil
+}
+
 func (m *Validator) IsValid(tjson *DJSON) bool {
+	if m.Schema != nil {
+		return m.Schema.IsValid(tjson)
+	}
+
 	if tjson == nil {
 		return len(m.RootItems) == 0
 	}
@@ -561,202 +633,203 @@ func (m *Validator) IsValid(tjson *DJSON) bool {
 }
 
 func CheckVItem(vi *VItem, tjson *DJSON) bool {
+	return checkVItem(vi, tjson, nil, nil)
+}
+
+// checkVItem reports to r every violation under vi when r is not nil, and
+// stops at the first one otherwise. path is the pointer of tjson.
+
+func checkVItem(vi *VItem, tjson *DJSON, path []interface{}, r *vreport) bool {
 	if vi.Name == "" {
 		return false
 	}
 
-	var vtype string
+	var key []interface{}
+	vpath := path
 
-	if vi.Name == "__root__" || vi.Name == "__array__" {
-		vtype = tjson.GetType()
-	} else {
-		vtype = tjson.GetType(vi.Name)
+	if vi.Name != "__root__" && vi.Name != "__array__" {
+		key = []interface{}{vi.Name}
+		vpath = appendPath(path, vi.Name)
 	}
 
+	vtype := tjson.GetType(key...)
+
 	//log.Println("CheckVItem ", vi.Name, " ", vtype, " ", vi.Type, " ", tjson.ToString())
 
-	if vtype == "" && !vi.IsRequred {
-		return true
+	if vtype == "" {
+		if !vi.IsRequred {
+			return true
+		}
+		return r.fail(vpath, "required", V_ERR_REQUIRED, true, nil)
+	}
+
+	if vi.values != nil && !vi.values.checkValue(tjson.GetAsInterface(key...), vpath, r) {
+		return false
 	}
 
 	switch vi.Type {
 	case V_TYPE_INT:
 		if vtype != "int" {
-			return false
+			return r.fail(vpath, "type", V_ERR_TYPE, vTypeNames[vi.Type], vtype)
 		}
 
-		var si int64
+		si := tjson.GetAsInt(key...)
 
-		if vi.Name == "__root__" || vi.Name == "__array__" {
-			si = tjson.GetAsInt()
-		} else {
-			si = tjson.GetAsInt(vi.Name)
+		if vi.Min > si {
+			return r.fail(vpath, "min", V_ERR_TOO_SMALL, vi.Min, si)
 		}
-
-		if vi.Max < si || vi.Min > si {
-			return false
+		if vi.Max < si {
+			return r.fail(vpath, "max", V_ERR_TOO_LARGE, vi.Max, si)
 		}
 
-	case V_TYPE_NUMBER:
-		if vtype != "float" && vtype != "int" {
-			return false
+		if vi.CheckFunc != nil && !vi.CheckFunc(strconv.FormatInt(si, 10), vi.Min, vi.Max) {
+			return r.fail(vpath, "format", V_ERR_FORMAT, vi.TypeName, si)
 		}
 
-		fallthrough
-
-	case V_TYPE_FLOAT:
+	case V_TYPE_NUMBER, V_TYPE_FLOAT:
 		if vtype != "float" {
-			return false
+			return r.fail(vpath, "type", V_ERR_TYPE, vTypeNames[vi.Type], vtype)
 		}
 
-		var sf float64
+		sf := tjson.GetAsFloat(key...)
 
-		if vi.Name == "__root__" || vi.Name == "__array__" {
-			sf = tjson.GetAsFloat()
-		} else {
-			sf = tjson.GetAsFloat(vi.Name)
+		if vi.MinFloat > sf {
+			return r.fail(vpath, "min", V_ERR_TOO_SMALL, vi.MinFloat, sf)
+		}
+		if vi.MaxFloat < sf {
+			return r.fail(vpath, "max", V_ERR_TOO_LARGE, vi.MaxFloat, sf)
 		}
 
-		if vi.MaxFloat < sf || vi.MinFloat > sf {
-			return false
+		if vi.CheckFloatFunc != nil && !vi.CheckFloatFunc(strconv.FormatFloat(sf, 'f', -1, 64), vi.MinFloat, vi.MaxFloat) {
+			return r.fail(vpath, "format", V_ERR_FORMAT, vi.TypeName, sf)
 		}
+
 	case V_TYPE_STRING:
 		if vtype != "string" {
-			return false
-		}
-
-		var ss string
-
-		if vi.Name == "__root__" || vi.Name == "__array__" {
-			ss = tjson.GetAsString()
-		} else {
-			ss = tjson.GetAsString(vi.Name)
+			return r.fail(vpath, "type", V_ERR_TYPE, vTypeNames[vi.Type], vtype)
 		}
 
+		ss := tjson.GetAsString(key...)
 		lenv := int64(len(ss))
 
-		if lenv > vi.Max || lenv < vi.Min {
-			return false
+		if lenv < vi.Min {
+			return r.fail(vpath, "min", V_ERR_TOO_SHORT, vi.Min, lenv)
+		}
+		if lenv > vi.Max {
+			return r.fail(vpath, "max", V_ERR_TOO_LONG, vi.Max, lenv)
 		}
 
 		if vi.RegExp != nil {
-			return vi.RegExp.Match([]byte(ss))
+			if !vi.RegExp.MatchString(ss) {
+				return r.fail(vpath, "regexp", V_ERR_PATTERN, vi.RegExp.String(), ss)
+			}
+			return true
 		}
 
-		if vi.CheckFunc != nil {
-			return vi.CheckFunc(ss, vi.Min, vi.Max)
+		if vi.CheckFunc != nil && !vi.CheckFunc(ss, vi.Min, vi.Max) {
+			return r.fail(vpath, "format", V_ERR_FORMAT, vi.TypeName, ss)
 		}
 
 	case V_TYPE_OBJECT:
-		if vi.Name == "__root__" && vtype != "object" {
-			return false
+		so, ok := tjson.GetAsObject(key...)
+		if !ok {
+			if key != nil && !vi.IsRequred {
+				return true // an optional field of another type is let through
+			}
+			return r.fail(vpath, "type", V_ERR_TYPE, vTypeNames[vi.Type], vtype)
 		}
 
-		var so *DJSON
-		var ok bool
-
-		if vi.Name == "__root__" || vi.Name == "__array__" {
-			so, ok = tjson.GetAsObject()
-			if !ok {
-				return false
+		valid := true
+		for _, svi := range vi.SubItems {
+			if !checkVItem(svi, so, vpath, r) {
+				if r == nil {
+					return false
+				}
+				valid = false
 			}
-		} else {
-			so, ok = tjson.GetAsObject(vi.Name)
 		}
 
-		if vi.IsRequred && !ok {
+		if vi.constraints != nil && !vi.constraints.check(so, vpath, r) {
 			return false
 		}
+		return valid
 
+	case V_TYPE_ARRAY:
+		sa, ok := tjson.GetAsArray(key...)
 		if !ok {
-			return true
-		}
-
-		for _, svi := range vi.SubItems {
-			if !CheckVItem(svi, so) {
-				return false
+			if key != nil && !vi.IsRequred {
+				return true // an optional field of another type is let through
 			}
+			return r.fail(vpath, "type", V_ERR_TYPE, vTypeNames[vi.Type], vtype)
 		}
 
-	case V_TYPE_ARRAY:
-		if vi.Name == "__root__" && vtype != "array" {
-			return false
+		lenv := int64(sa.Length())
+		if lenv < vi.Min {
+			return r.fail(vpath, "min", V_ERR_TOO_FEW, vi.Min, lenv)
+		}
+		if lenv > vi.Max {
+			return r.fail(vpath, "max", V_ERR_TOO_MANY, vi.Max, lenv)
 		}
 
-		var sa *DJSON
-		var ok bool
+		valid := true
 
-		if vi.Name == "__root__" || vi.Name == "__array__" {
-			sa, ok = tjson.GetAsArray()
-			if !ok {
+		if vi.values != nil && !vi.values.checkUnique(sa.Array, vpath, r) {
+			if r == nil {
 				return false
 			}
-		} else {
-			sa, ok = tjson.GetAsArray(vi.Name)
-		}
-
-		if vi.IsRequred && !ok {
-			return false
+			valid = false
 		}
 
-		if !ok {
-			return true
+		if len(vi.SubItems) == 0 {
+			return valid
 		}
 
-		lenv := int64(sa.Length())
-		if lenv > vi.Max || lenv < vi.Min {
-			return false
-		}
-
-		if ok {
-			if len(vi.SubItems) == 0 {
-				return true
-			}
-
-			sa.Seek() // valid element type
-			for ssa := sa.Next(); ssa != nil; ssa = sa.Next() {
-				isValid := false
-				for _, svi := range vi.SubItems {
-					if CheckVItem(svi, ssa) {
-						isValid = true
-						break
-					}
-				}
-				if !isValid {
+		idx := 0
+		sa.Seek() // valid element type
+		for ssa := sa.Next(); ssa != nil; ssa = sa.Next() {
+			if !checkAlternatives(vi.SubItems, ssa, appendPath(vpath, idx), appendPath(vpath, idx), r) {
+				if r == nil {
 					return false
 				}
+				valid = false
 			}
+			idx++
 		}
+		return valid
 
 	case V_TYPE_BOOL:
-
-		var ok bool
-		if vi.Name == "__root__" || vi.Name == "__array__" {
-			ok = tjson.IsBool()
-		} else {
-			ok = tjson.IsBool(vi.Name)
-		}
-
-		if vi.IsRequred && !ok {
-			return false
+		if vi.IsRequred && !tjson.IsBool(key...) {
+			return r.fail(vpath, "type", V_ERR_TYPE, vTypeNames[vi.Type], vtype)
 		}
 
 	case V_TYPE_MULTI:
+		return checkAlternatives(vi.SubItems, tjson, path, vpath, r)
+	}
 
-		isValid := false
+	return true
+}
 
-		for _, svi := range vi.SubItems {
-			if CheckVItem(svi, tjson) {
-				isValid = true
-				break
-			}
-		}
+// checkAlternatives passes if tjson is valid for one of items. A single
+// item reports its own violations; otherwise the value is reported as
+// matching none of them.
 
-		if !isValid {
-			return false
+func checkAlternatives(items []*VItem, tjson *DJSON, path, vpath []interface{}, r *vreport) bool {
+	if len(items) == 1 {
+		return checkVItem(items[0], tjson, path, r)
+	}
+
+	expected := make([]string, 0, len(items))
+	for _, svi := range items {
+		if checkVItem(svi, tjson, path, nil) {
+			return true
 		}
+		expected = append(expected, svi.typeName())
+	}
 
+	var key []interface{}
+	if len(items) > 0 && items[0].Name != "__root__" && items[0].Name != "__array__" {
+		key = []interface{}{items[0].Name}
 	}
 
-	return true
+	return r.fail(vpath, "type", V_ERR_NO_MATCH, expected, tjson.GetType(key...))
 }
@@ -0,0 +1,476 @@
+package djson
+
+import (
+	"regexp"
+	"sort"
+)
+
+var compareExprRegExp = regexp.MustCompile(`^\s*(\S+)\s*(==|!=|>=|<=|>|<)\s*(\S+)\s*$`)
+
+// vconstraints are the cross-field rules of an OBJECT item, given next to
+// "object" in the syntax:
+//
+//	"conditions": [{"if": {"type": "card"}, "then": {"required": ["card_no"]}, "else": {"forbidden": ["card_no"]}}]
+//	"exclusive": [["email", "phone"]]       at most one present
+//	"exactlyOne": [["email", "phone"]]
+//	"atLeastOne": [["email", "phone"]]
+//	"together": [["lat", "lng"]]            all or none present
+//	"dependencies": {"card_no": ["card_exp"]}
+//	"compare": ["end_date >= start_date"]
+//
+// "if" holds when every field listed equals its value; a missing field
+// equals null. A group may also be given as a single list of names. A
+// field is present if it is set and not null. Comparisons take numbers by
+// value and strings rune by rune, so dates in YYYYMMDD or ISO 8601 compare
+// as dates; they are skipped unless both fields are present. The rules may
+// be given without "object". A malformed rule makes Compile fail.
+
+type vconstraints struct {
+	conditions   []*vcondition
+	groups       []*vgroup
+	dependencies []*vdependency
+	comparisons  []*vcomparison
+}
+
+type vcondition struct {
+	fields    []string
+	values    []string
+	then      *vrequirement
+	otherwise *vrequirement
+}
+
+type vrequirement struct {
+	required  []string
+	forbidden []string
+}
+
+type vgroup struct {
+	rule   string
+	fields []string
+}
+
+type vdependency struct {
+	field    string
+	requires []string
+}
+
+type vcomparison struct {
+	expr  string
+	left  string
+	op    string
+	right string
+}
+
+func getStringList(element interface{}) ([]string, bool) {
+	arr, ok := element.(*DA)
+	if !ok {
+		return nil, false
+	}
+
+	list := make([]string, 0, arr.Size())
+	for idx := range arr.Element {
+		s, ok := arr.Element[idx].(string)
+		if !ok {
+			return nil, false
+		}
+		list = append(list, s)
+	}
+
+	return list, true
+}
+
+// getStringListAt is getStringList for an optional entry of obj; a missing
+// entry is an empty list.
+
+func getStringListAt(obj *DO, key string) ([]string, bool) {
+	element, ok := obj.Map[key]
+	if !ok {
+		return nil, true
+	}
+	return getStringList(element)
+}
+
+func getVRequirement(element interface{}) (*vrequirement, bool) {
+	if element == nil {
+		return nil, true
+	}
+
+	obj, ok := element.(*DO)
+	if !ok {
+		return nil, false
+	}
+
+	req := &vrequirement{}
+	if req.required, ok = getStringListAt(obj, "required"); !ok {
+		return nil, false
+	}
+	if req.forbidden, ok = getStringListAt(obj, "forbidden"); !ok {
+		return nil, false
+	}
+	return req, true
+}
+
+// getVConstraints returns the cross-field rules of an OBJECT item, nil if
+// it has none, and false if one of them is malformed.
+
+func getVConstraints(ejson *DJSON) (*vconstraints, bool) {
+	obj, ok := ejson.GetAsInterface().(*DO)
+	if !ok {
+		return nil, true
+	}
+
+	c := &vconstraints{}
+
+	if element, ok := obj.Map["conditions"]; ok {
+		arr, ok := element.(*DA)
+		if !ok {
+			return nil, false
+		}
+		for idx := range arr.Element {
+			cobj, ok := arr.Element[idx].(*DO)
+			if !ok {
+				return nil, false
+			}
+			when, ok := cobj.Map["if"].(*DO)
+			if !ok {
+				return nil, false
+			}
+
+			cond := &vcondition{}
+			if cond.then, ok = getVRequirement(cobj.Map["then"]); !ok {
+				return nil, false
+			}
+			if cond.otherwise, ok = getVRequirement(cobj.Map["else"]); !ok {
+				return nil, false
+			}
+			for _, k := range when.Keys() {
+				cond.fields = append(cond.fields, k)
+				cond.values = append(cond.values, canonicalKey(when.Map[k]))
+			}
+			c.conditions = append(c.conditions, cond)
+		}
+	}
+
+	for _, rule := range []string{"exclusive", "exactlyOne", "atLeastOne", "together"} {
+		element, ok := obj.Map[rule]
+		if !ok {
+			continue
+		}
+		arr, ok := element.(*DA)
+		if !ok {
+			return nil, false
+		}
+
+		if fields, ok := getStringList(arr); ok {
+			c.groups = append(c.groups, &vgroup{rule: rule, fields: fields})
+			continue
+		}
+
+		for idx := range arr.Element {
+			fields, ok := getStringList(arr.Element[idx])
+			if !ok {
+				return nil, false
+			}
+			c.groups = append(c.groups, &vgroup{rule: rule, fields: fields})
+		}
+	}
+
+	if element, ok := obj.Map["dependencies"]; ok {
+		deps, ok := element.(*DO)
+		if !ok {
+			return nil, false
+		}
+
+		keys := deps.Keys()
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			requires, ok := getStringList(deps.Map[k])
+			if !ok {
+				return nil, false
+			}
+			c.dependencies = append(c.dependencies, &vdependency{field: k, requires: requires})
+		}
+	}
+
+	exprs, ok := getStringListAt(obj, "compare")
+	if !ok {
+		return nil, false
+	}
+	for _, expr := range exprs {
+		m := compareExprRegExp.FindStringSubmatch(expr)
+		if m == nil {
+			return nil, false
+		}
+		c.comparisons = append(c.comparisons, &vcomparison{expr: expr, left: m[1], op: m[2], right: m[3]})
+	}
+
+	if len(c.conditions)+len(c.groups)+len(c.dependencies)+len(c.comparisons) == 0 {
+		return nil, true
+	}
+	return c, true
+}
+
+func presentField(obj *DO, field string) (interface{}, bool) {
+	v, ok := obj.Map[field]
+	return v, ok && v != nil
+}
+
+func (c *vconstraints) check(so *DJSON, path []interface{}, r *vreport) bool {
+	obj, ok := so.GetAsInterface().(*DO)
+	if !ok {
+		return true
+	}
+
+	valid := true
+	fail := func(ok bool) bool {
+		if !ok {
+			valid = false
+		}
+		return !ok && r == nil
+	}
+
+	for _, cond := range c.conditions {
+		req := cond.then
+		for idx, k := range cond.fields {
+			if canonicalKey(obj.Map[k]) != cond.values[idx] {
+				req = cond.otherwise
+				break
+			}
+		}
+		if req == nil {
+			continue
+		}
+
+		for _, k := range req.required {
+			if _, ok := presentField(obj, k); !ok {
+				if fail(r.fail(appendPath(path, k), "conditions", V_ERR_REQUIRED, true, nil)) {
+					return false
+				}
+			}
+		}
+		for _, k := range req.forbidden {
+			if v, ok := presentField(obj, k); ok {
+				if fail(r.fail(appendPath(path, k), "conditions", V_ERR_FORBIDDEN, false, v)) {
+					return false
+				}
+			}
+		}
+	}
+
+	for _, group := range c.groups {
+		present := make([]string, 0, len(group.fields))
+		for _, k := range group.fields {
+			if _, ok := presentField(obj, k); ok {
+				present = append(present, k)
+			}
+		}
+
+		switch {
+		case len(present) > 1 && (group.rule == "exclusive" || group.rule == "exactlyOne"):
+			if fail(r.fail(path, group.rule, V_ERR_EXCLUSIVE, group.fields, present)) {
+				return false
+			}
+		case len(present) == 0 && (group.rule == "exactlyOne" || group.rule == "atLeastOne"):
+			if fail(r.fail(path, group.rule, V_ERR_REQUIRED, group.fields, nil)) {
+				return false
+			}
+		case len(present) > 0 && len(present) < len(group.fields) && group.rule == "together":
+			for _, k := range group.fields {
+				if _, ok := presentField(obj, k); !ok {
+					if fail(r.fail(appendPath(path, k), group.rule, V_ERR_REQUIRED, true, nil)) {
+						return false
+					}
+				}
+			}
+		}
+	}
+
+	for _, dep := range c.dependencies {
+		if _, ok := presentField(obj, dep.field); !ok {
+			continue
+		}
+		for _, k := range dep.requires {
+			if _, ok := presentField(obj, k); !ok {
+				if fail(r.fail(appendPath(path, k), "dependencies", V_ERR_REQUIRED, true, nil)) {
+					return false
+				}
+			}
+		}
+	}
+
+	for _, cmp := range c.comparisons {
+		left, lok := presentField(obj, cmp.left)
+		right, rok := presentField(obj, cmp.right)
+		if !lok || !rok {
+			continue
+		}
+
+		if !cmp.holds(left, right) {
+			if fail(r.fail(appendPath(path, cmp.left), "compare", V_ERR_COMPARE, cmp.expr, left)) {
+				return false
+			}
+		}
+	}
+
+	return valid
+}
+
+// holds compares numbers with numbers and strings with strings; other
+// operands never satisfy the comparison.
+
+func (cmp *vcomparison) holds(left, right interface{}) bool {
+	rank := elementRank(left)
+	if rank != elementRank(right) || (rank != 1 && rank != 2) {
+		return false
+	}
+
+	c := compareElements(left, right)
+
+	switch cmp.op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case ">=":
+		return c >= 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case "<":
+		return c < 0
+	}
+	return false
+}
+
+// vvalues are the value rules of an item: "enum" lists the allowed values
+// and "const" the only one, compared as canonical JSON so that 1 equals
+// 1.0 and objects match whatever their key order. On ARRAY items,
+// "unique": true requires distinct elements and "uniqueBy" distinct values
+// of a field or a list of fields, such as "id" or ["user.id", "type"];
+// elements missing all of them are not compared.
+
+type vvalues struct {
+	enum     []string
+	enumRaw  interface{}
+	hasConst bool
+	constKey string
+	constRaw interface{}
+	unique   bool
+	uniqueBy []string
+}
+
+func getVValues(ejson *DJSON) *vvalues {
+	obj, ok := ejson.GetAsInterface().(*DO)
+	if !ok {
+		return nil
+	}
+
+	vv := &vvalues{}
+	found := false
+
+	if arr, ok := obj.Map["enum"].(*DA); ok {
+		vv.enumRaw = arr
+		vv.enum = make([]string, 0, arr.Size())
+		for idx := range arr.Element {
+			vv.enum = append(vv.enum, canonicalKey(arr.Element[idx]))
+		}
+		found = true
+	}
+
+	if v, ok := obj.Map["const"]; ok {
+		vv.hasConst = true
+		vv.constRaw = v
+		vv.constKey = canonicalKey(v)
+		found = true
+	}
+
+	if b, ok := obj.Map["unique"].(bool); ok && b {
+		vv.unique = true
+		found = true
+	}
+
+	switch t := obj.Map["uniqueBy"].(type) {
+	case string:
+		vv.uniqueBy = []string{t}
+		found = true
+	case *DA:
+		if fields, ok := getStringList(t); ok && len(fields) > 0 {
+			vv.uniqueBy = fields
+			found = true
+		}
+	}
+
+	if !found {
+		return nil
+	}
+	return vv
+}
+
+func (vv *vvalues) checkValue(v interface{}, path []interface{}, r *vreport) bool {
+	if vv.enum == nil && !vv.hasConst {
+		return true
+	}
+
+	key := canonicalKey(v)
+
+	if vv.enum != nil {
+		found := false
+		for _, e := range vv.enum {
+			if e == key {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return r.fail(path, "enum", V_ERR_ENUM, vv.enumRaw, v)
+		}
+	}
+
+	if vv.hasConst && vv.constKey != key {
+		return r.fail(path, "const", V_ERR_CONST, vv.constRaw, v)
+	}
+
+	return true
+}
+
+func (vv *vvalues) checkUnique(arr *DA, path []interface{}, r *vreport) bool {
+	if !vv.unique && len(vv.uniqueBy) == 0 {
+		return true
+	}
+
+	valid := true
+	seen := make(map[string]int, arr.Size())
+
+	for idx := range arr.Element {
+		rule := "unique"
+		key := canonicalKey(arr.Element[idx])
+
+		if len(vv.uniqueBy) > 0 {
+			rule = "uniqueBy"
+			values := make([]interface{}, len(vv.uniqueBy))
+			missing := true
+			for k, field := range vv.uniqueBy {
+				if values[k] = fieldValue(arr.Element[idx], field); values[k] != nil {
+					missing = false
+				}
+			}
+			if missing {
+				continue
+			}
+			key = canonicalKey(&DA{Element: values})
+		}
+
+		if first, ok := seen[key]; ok {
+			valid = r.fail(appendPath(path, idx), rule, V_ERR_NOT_UNIQUE, BuildPointer(appendPath(path, first)...), arr.Element[idx])
+			if r == nil {
+				return false
+			}
+			continue
+		}
+		seen[key] = idx
+	}
+
+	return valid
+}
@@ -0,0 +1,250 @@
+package djson
+
+import (
+	"testing"
+)
+
+const testConstraintSyntax = `{
+	"type": "OBJECT",
+	"object": {
+		"type": {"type": "STRING", "required": true},
+		"card_no": "STRING",
+		"account": "STRING",
+		"email": "EMAIL",
+		"phone": "TELEPHONE",
+		"lat": "FLOAT",
+		"lng": "FLOAT",
+		"coupon": "STRING",
+		"coupon_exp": "YYYYMMDD",
+		"start_date": "YYYYMMDD",
+		"end_date": "YYYYMMDD",
+		"min_price": "INT",
+		"max_price": "INT"
+	},
+	"conditions": [
+		{"if": {"type": "card"}, "then": {"required": ["card_no"]}, "else": {"forbidden": ["card_no"]}},
+		{"if": {"type": "bank"}, "then": {"required": ["account"]}}
+	],
+	"exactlyOne": [["email", "phone"]],
+	"together": ["lat", "lng"],
+	"dependencies": {"coupon": ["coupon_exp"]},
+	"compare": ["end_date >= start_date", "max_price > min_price"]
+}`
+
+func TestValidatorConstraints(t *testing.T) {
+	dv := NewValidator()
+	dv.Compile(testConstraintSyntax)
+
+	for _, s := range []string{
+		`{"type": "card", "card_no": "1234", "email": "wake@example.com"}`,
+		`{"type": "bank", "account": "1234", "phone": "010-1234-5678", "lat": 37.5, "lng": 127.0}`,
+		`{"type": "cash", "email": "wake@example.com", "coupon": "A", "coupon_exp": "20241231"}`,
+		`{"type": "cash", "email": "wake@example.com", "start_date": "20240101", "end_date": "20240101", "min_price": 1, "max_price": 2}`,
+		`{"type": "cash", "email": "wake@example.com", "end_date": "20240101", "max_price": 2}`,
+	} {
+		if errs := dv.Validate(NewDJSON().Parse(s)); len(errs) != 0 || !dv.IsValid(NewDJSON().Parse(s)) {
+			t.Fatal(s, errs)
+		}
+	}
+
+	for s, expected := range map[string]map[string]string{
+		`{"type": "card", "email": "wake@example.com"}`: {
+			"/card_no": V_ERR_REQUIRED,
+		},
+		`{"type": "bank", "card_no": "1234", "phone": "010-1234-5678"}`: {
+			"/card_no": V_ERR_FORBIDDEN,
+			"/account": V_ERR_REQUIRED,
+		},
+		`{"type": "cash", "email": "wake@example.com", "phone": "010-1234-5678", "lat": 37.5}`: {
+			"":     V_ERR_EXCLUSIVE,
+			"/lng": V_ERR_REQUIRED,
+		},
+		`{"type": "cash", "email": "wake@example.com", "phone": null}`: {
+			"/phone": V_ERR_TYPE,
+		},
+		`{"type": "cash", "coupon": "A", "start_date": "20240102", "end_date": "20240101", "min_price": 2, "max_price": 2}`: {
+			"":            V_ERR_REQUIRED,
+			"/coupon_exp": V_ERR_REQUIRED,
+			"/end_date":   V_ERR_COMPARE,
+			"/max_price":  V_ERR_COMPARE,
+		},
+	} {
+		doc := NewDJSON().Parse(s)
+		if dv.IsValid(doc) {
+			t.Fatal(s)
+		}
+
+		errs := dv.Validate(doc)
+		if len(errs) != len(expected) {
+			t.Fatal(s, errs)
+		}
+		for _, e := range errs {
+			if expected[e.Path] != e.Code {
+				t.Fatal(s, e.Error())
+			}
+		}
+	}
+
+	errs := dv.Validate(NewDJSON().Parse(`{"type": "cash", "email": "wake@example.com", "start_date": "20240102", "end_date": "20240101"}`))
+	if len(errs) != 1 || errs[0].Error() != `/end_date: comparison (expected end_date >= start_date, got 20240101)` || errs[0].Rule != "compare" {
+		t.Fatal(errs)
+	}
+
+	errs = dv.Validate(NewDJSON().Parse(`{"type": "cash", "email": "wake@example.com", "min_price": "1", "max_price": 2}`))
+	if len(errs) != 2 {
+		t.Fatal(errs)
+	}
+}
+
+func TestValidatorConstraintsNested(t *testing.T) {
+	dv := NewValidator()
+	dv.Compile(`{
+		"type": "OBJECT",
+		"object": {
+			"items": {
+				"type": "ARRAY",
+				"array": {
+					"type": "OBJECT",
+					"object": {"from": "INT", "to": "INT"},
+					"compare": ["to >= from"],
+					"exclusive": ["a", "b"]
+				}
+			}
+		}
+	}`)
+
+	errs := dv.Validate(NewDJSON().Parse(`{"items": [{"from": 1, "to": 2}, {"from": 3, "to": 2, "a": 1, "b": 2}]}`))
+	if len(errs) != 2 || errs[0].Path != "/items/1" || errs[1].Path != "/items/1/to" {
+		t.Fatal(errs)
+	}
+}
+
+func TestValidatorConstraintsOnly(t *testing.T) {
+	dv := NewValidator()
+	if !dv.Compile(`{"type": "OBJECT", "exactlyOne": ["email", "phone"]}`) {
+		t.Fatal("expected the syntax to compile")
+	}
+
+	if dv.IsValid(NewDJSON().Parse(`{}`)) || dv.IsValid(NewDJSON().Parse(`[]`)) || !dv.IsValid(NewDJSON().Parse(`{"email": "wake@example.com"}`)) {
+		t.Fatal(dv.Validate(NewDJSON().Parse(`{}`)))
+	}
+
+	for _, s := range []string{
+		`{"type": "OBJECT", "object": {"a": "INT"}, "compare": ["a => 1"]}`,
+		`{"type": "OBJECT", "compare": "a > b"}`,
+		`{"type": "OBJECT", "conditions": [{"if": "a"}]}`,
+		`{"type": "OBJECT", "conditions": [{"if": {"a": 1}, "then": ["b"]}]}`,
+		`{"type": "OBJECT", "conditions": {"if": {"a": 1}}}`,
+		`{"type": "OBJECT", "exclusive": [["a", 1]]}`,
+		`{"type": "OBJECT", "together": "a"}`,
+		`{"type": "OBJECT", "dependencies": {"a": "b"}}`,
+		`{"type": "OBJECT", "object": {"items": {"type": "ARRAY", "array": {"type": "OBJECT", "atLeastOne": [1, 2]}}}}`,
+	} {
+		if NewValidator().Compile(s) {
+			t.Fatal(s)
+		}
+	}
+}
+
+func TestValidatorEnumUnique(t *testing.T) {
+	dv := NewValidator()
+	dv.Compile(`{
+		"type": "OBJECT",
+		"object": {
+			"status": {"type": "STRING", "enum": ["open", "closed"], "required": true},
+			"level": {"type": "NUMBER", "enum": [1, 2, 3]},
+			"version": {"type": "INT", "const": 2},
+			"origin": {"type": "OBJECT", "object": {}, "const": {"x": 0, "y": 0}},
+			"tags": {"type": "ARRAY", "unique": true, "array": {"type": "STRING", "enum": ["a", "b", "c"]}},
+			"users": {
+				"type": "ARRAY",
+				"uniqueBy": "id",
+				"array": {"type": "OBJECT", "object": {"id": "INT", "role": {"type": "STRING", "enum": ["admin", "user"]}}}
+			},
+			"pairs": {"type": "ARRAY", "uniqueBy": ["a.b", "c"]}
+		}
+	}`)
+
+	valid := NewDJSON().Parse(`{
+		"status": "open",
+		"level": 2.0,
+		"version": 2,
+		"origin": {"y": 0, "x": 0},
+		"tags": ["a", "b"],
+		"users": [{"id": 1, "role": "admin"}, {"id": 2, "role": "user"}, {"role": "user"}, {"role": "user"}],
+		"pairs": [{"a": {"b": 1}, "c": 1}, {"a": {"b": 1}, "c": 2}, {"a": {"b": 2}, "c": 1}]
+	}`)
+	if errs := dv.Validate(valid); len(errs) != 0 || !dv.IsValid(valid) {
+		t.Fatal(errs)
+	}
+
+	invalid := NewDJSON().Parse(`{
+		"status": "draft",
+		"level": 4,
+		"version": 3,
+		"origin": {"x": 1, "y": 0},
+		"tags": ["a", "d", "a"],
+		"users": [{"id": 1, "role": "admin"}, {"id": 1, "role": "guest"}],
+		"pairs": [{"a": {"b": 1}, "c": 1}, {"a": {"b": 1}, "c": 1.0}]
+	}`)
+	if dv.IsValid(invalid) {
+		t.Fatal(invalid.ToString())
+	}
+
+	expected := map[string]string{
+		"/status":       V_ERR_ENUM,
+		"/level":        V_ERR_ENUM,
+		"/version":      V_ERR_CONST,
+		"/origin":       V_ERR_CONST,
+		"/tags/2":       V_ERR_NOT_UNIQUE,
+		"/tags/1":       V_ERR_ENUM,
+		"/users/1":      V_ERR_NOT_UNIQUE,
+		"/users/1/role": V_ERR_ENUM,
+		"/pairs/1":      V_ERR_NOT_UNIQUE,
+	}
+
+	errs := dv.Validate(invalid)
+	if len(errs) != len(expected) {
+		t.Fatal(errs)
+	}
+	for _, e := range errs {
+		if expected[e.Path] != e.Code {
+			t.Fatal(e.Error())
+		}
+
+		switch e.Path {
+		case "/status":
+			if e.ToDJSON().ToString() != `{"path":"/status","rule":"enum","code":"enum","expected":["open","closed"],"actual":"draft"}` {
+				t.Fatal(e.ToDJSON().ToString())
+			}
+		case "/users/1":
+			if e.Rule != "uniqueBy" || e.Expected != "/users/0" {
+				t.Fatal(e.Error())
+			}
+		}
+	}
+}
+
+func TestValidatorUniqueExact(t *testing.T) {
+	dv := NewValidator()
+	dv.Compile(`{"type": "OBJECT", "object": {"ids": {"type": "ARRAY", "unique": true}, "code": {"type": "INT", "max": 9223372036854775807, "enum": [9007199254740993]}}}`)
+
+	if !dv.IsValid(NewDJSON().Parse(`{"ids": [9007199254740993, 9007199254740992, "9007199254740993"], "code": 9007199254740993}`)) {
+		t.Fatal()
+	}
+
+	errs := dv.Validate(NewDJSON().Parse(`{"ids": [1, 1.0], "code": 9007199254740992}`))
+	if len(errs) != 2 {
+		t.Fatal(errs)
+	}
+	for _, e := range errs {
+		if (e.Path != "/ids/1" || e.Code != V_ERR_NOT_UNIQUE) && (e.Path != "/code" || e.Code != V_ERR_ENUM) {
+			t.Fatal(e.Error())
+		}
+	}
+
+	schema, _ := CompileSchema(NewDJSON().Parse(`{"uniqueItems": true}`))
+	if !schema.IsValid(NewDJSON().Parse(`[9007199254740993, 9007199254740992]`)) {
+		t.Fatal()
+	}
+}
@@ -0,0 +1,165 @@
+package djson
+
+import (
+	"fmt"
+)
+
+const (
+	V_ERR_REQUIRED  = "required"       // required value is missing
+	V_ERR_TYPE      = "type_mismatch"  // value is of another JSON type
+	V_ERR_NO_MATCH  = "no_match"       // value matches none of the alternatives
+	V_ERR_TOO_SMALL = "too_small"      // number below min
+	V_ERR_TOO_LARGE = "too_large"      // number above max
+	V_ERR_TOO_SHORT = "too_short"      // string shorter than min
+	V_ERR_TOO_LONG  = "too_long"       // string longer than max
+	V_ERR_TOO_FEW   = "too_few_items"  // array shorter than min
+	V_ERR_TOO_MANY  = "too_many_items" // array longer than max
+	V_ERR_PATTERN   = "pattern"        // string does not match regexp
+	V_ERR_FORMAT    = "format"         // string fails the check of its type
+
+	V_ERR_ENUM           = "enum"                // value is not one of enum
+	V_ERR_CONST          = "const"               // value is not const
+	V_ERR_NOT            = "not"                 // value matches the schema of not
+	V_ERR_MULTI_MATCH    = "multiple_match"      // value matches more than one of oneOf
+	V_ERR_ADDITIONAL     = "additional_property" // property not allowed by additionalProperties
+	V_ERR_NOT_UNIQUE     = "not_unique"          // array item equal to an earlier one
+	V_ERR_MULTIPLE_OF    = "multiple_of"         // number is not a multiple of multipleOf
+	V_ERR_FALSE          = "false_schema"        // value is checked against the false schema
+	V_ERR_TOO_FEW_PROPS  = "too_few_properties"  // object has fewer properties than min
+	V_ERR_TOO_MANY_PROPS = "too_many_properties" // object has more properties than max
+
+	V_ERR_FORBIDDEN = "forbidden"  // field present where a condition forbids it
+	V_ERR_EXCLUSIVE = "exclusive"  // more than one field of an exclusive group present
+	V_ERR_COMPARE   = "comparison" // comparison between fields does not hold
+)
+
+var vTypeNames = map[int]string{
+	V_TYPE_NULL:   "null",
+	V_TYPE_INT:    "int",
+	V_TYPE_FLOAT:  "float",
+	V_TYPE_NUMBER: "number",
+	V_TYPE_STRING: "string",
+	V_TYPE_BOOL:   "bool",
+	V_TYPE_OBJECT: "object",
+	V_TYPE_ARRAY:  "array",
+	V_TYPE_MULTI:  "multi",
+}
+
+// ValidationError is a single violation found by Validate. Path is the
+// JSON pointer of the value, "" for the root. Rule is the part of the
+// syntax that failed: "required", "type", "min", "max", "regexp",
+// "format", "enum", "const", "unique", "uniqueBy" or the name of an object
+// constraint such as "conditions" or "compare"; for a Schema it is the
+// failing keyword. Expected and Actual depend on the rule, e.g. the type
+// names, the bound and the number or length, or the pattern and the
+// string. For V_ERR_NO_MATCH, Expected lists the alternatives.
+
+type ValidationError struct {
+	Path     string
+	Rule     string
+	Code     string
+	Expected interface{}
+	Actual   interface{}
+}
+
+func (e *ValidationError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "/"
+	}
+
+	if e.Actual == nil {
+		return fmt.Sprintf("%s: %s (expected %v)", path, e.Code, e.Expected)
+	}
+	return fmt.Sprintf("%s: %s (expected %v, got %v)", path, e.Code, e.Expected, e.Actual)
+}
+
+func (e *ValidationError) ToDJSON() *DJSON {
+	obj := NewOrderedObject().
+		Put("path", e.Path).
+		Put("rule", e.Rule).
+		Put("code", e.Code)
+
+	if names, ok := e.Expected.([]string); ok {
+		obj.Put("expected", NewArray().Put(names))
+	} else {
+		obj.Put("expected", e.Expected)
+	}
+	obj.Put("actual", e.Actual)
+
+	ret, _ := elementToDJSON(obj)
+	return ret
+}
+
+type vreport struct {
+	errors []*ValidationError
+}
+
+// fail records a violation and returns false. It does nothing but return
+// false on a nil report.
+
+func (r *vreport) fail(path []interface{}, rule, code string, expected, actual interface{}) bool {
+	if r != nil {
+		r.errors = append(r.errors, &ValidationError{
+			Path:     BuildPointer(path...),
+			Rule:     rule,
+			Code:     code,
+			Expected: expected,
+			Actual:   actual,
+		})
+	}
+	return false
+}
+
+func (vi *VItem) typeName() string {
+	if vi.TypeName != "" {
+		return vi.TypeName
+	}
+	return vTypeNames[vi.Type]
+}
+
+// Validate checks tjson as IsValid does but goes on after a violation and
+// returns all of them in document order; it returns nil if tjson is valid.
+// When the syntax is a list of alternatives and tjson matches none, the
+// only violation is V_ERR_NO_MATCH at the root.
+
+func (m *Validator) Validate(tjson *DJSON) []*ValidationError {
+	if m.Schema != nil {
+		return m.Schema.Validate(tjson)
+	}
+
+	if tjson == nil {
+		if len(m.RootItems) == 0 {
+			return nil
+		}
+		return []*ValidationError{{Rule: "required", Code: V_ERR_REQUIRED, Expected: true}}
+	}
+
+	if len(m.RootItems) == 0 || (!m.Syntax.IsObject() && !m.Syntax.IsArray() && !m.Syntax.IsString()) {
+		return nil
+	}
+
+	r := &vreport{}
+
+	if m.Syntax.IsObject() {
+		checkVItem(m.RootItems[0], tjson, nil, r)
+	} else {
+		checkAlternatives(m.RootItems, tjson, nil, nil, r)
+	}
+
+	return r.errors
+}
+
+// ValidationReport returns the violations of Validate as an array of
+// objects with path, rule, code, expected and actual, ready to be sent in
+// an error response.
+
+func (m *Validator) ValidationReport(tjson *DJSON) *DJSON {
+	arr := NewArray()
+	for _, e := range m.Validate(tjson) {
+		arr.PushBack(e.ToDJSON())
+	}
+
+	ret, _ := elementToDJSON(arr)
+	return ret
+}
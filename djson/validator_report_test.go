@@ -0,0 +1,157 @@
+package djson
+
+import (
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	dv := NewValidator()
+	dv.Compile(`{
+		"type": "OBJECT",
+		"object": {
+			"name": {"type": "STRING", "min": 4, "max": 8, "required": true},
+			"age": {"type": "INT", "min": 0, "max": 150},
+			"email": {"type": "EMAIL", "required": true},
+			"code": {"type": "STRING", "regexp": "^[A-Z]{3}$"},
+			"score": "NUMBER",
+			"active": {"type": "BOOL", "required": true},
+			"profile": {
+				"type": "OBJECT",
+				"object": {
+					"nick": {"type": "NONEMPTY.STRING", "required": true}
+				}
+			},
+			"tags": {"type": "ARRAY", "max": 2, "array": {"type": "STRING", "max": 3}},
+			"ids": {"type": "ARRAY", "array": ["INT", "STRING"]}
+		}
+	}`)
+
+	valid := NewDJSON().Parse(`{"name": "wakeup", "age": 20, "email": "wake@example.com", "code": "ABC", "score": 1.5, "active": true,
+		"profile": {"nick": "w"}, "tags": ["a"], "ids": [1, "b"]}`)
+
+	if errs := dv.Validate(valid); len(errs) != 0 || !dv.IsValid(valid) {
+		t.Fatal(errs)
+	}
+
+	invalid := NewDJSON().Parse(`{"name": "abc", "age": 200, "email": "nope", "code": "abc", "score": "1", "active": 1,
+		"profile": {}, "tags": ["a", "abcd"], "ids": [1, {}]}`)
+
+	if dv.IsValid(invalid) {
+		t.Fatal(invalid.ToString())
+	}
+
+	expected := map[string]string{
+		"/name":         V_ERR_TOO_SHORT,
+		"/age":          V_ERR_TOO_LARGE,
+		"/email":        V_ERR_FORMAT,
+		"/code":         V_ERR_PATTERN,
+		"/score":        V_ERR_TYPE,
+		"/active":       V_ERR_TYPE,
+		"/profile/nick": V_ERR_REQUIRED,
+		"/tags/1":       V_ERR_TOO_LONG,
+		"/ids/1":        V_ERR_NO_MATCH,
+	}
+
+	errs := dv.Validate(invalid)
+	if len(errs) != len(expected) {
+		t.Fatal(errs)
+	}
+	for _, e := range errs {
+		if expected[e.Path] != e.Code {
+			t.Fatal(e.Error())
+		}
+	}
+
+	for _, e := range errs {
+		switch e.Path {
+		case "/age":
+			if e.Rule != "max" || e.Expected != int64(150) || e.Actual != int64(200) {
+				t.Fatal(e.Error())
+			}
+		case "/email":
+			if e.Rule != "format" || e.Expected != "EMAIL" || e.Actual != "nope" {
+				t.Fatal(e.Error())
+			}
+		case "/score":
+			if e.Expected != "number" || e.Actual != "string" {
+				t.Fatal(e.Error())
+			}
+		}
+	}
+
+	report := dv.ValidationReport(invalid)
+	if report.Length() != len(expected) {
+		t.Fatal(report.ToString())
+	}
+
+	for idx := 0; idx < report.Length(); idx++ {
+		item, _ := report.GetAsObject(idx)
+		if item.GetAsString("path") == "/ids/1" && item.ToString() != `{"path":"/ids/1","rule":"type","code":"no_match","expected":["INT","STRING"],"actual":"object"}` {
+			t.Fatal(item.ToString())
+		}
+	}
+
+	tooMany := valid.Clone()
+	tooMany.Put("tags", NewArray().Put([]string{"a", "b", "c"}))
+	if errs := dv.Validate(tooMany); len(errs) != 1 || errs[0].Code != V_ERR_TOO_MANY || errs[0].Actual != int64(3) {
+		t.Fatal(errs)
+	}
+}
+
+func TestValidateRoot(t *testing.T) {
+	dv := NewValidator()
+	dv.Compile(`["INT", "STRING"]`)
+
+	if errs := dv.Validate(NewDJSON().Parse(`"a"`)); errs != nil {
+		t.Fatal(errs)
+	}
+
+	errs := dv.Validate(NewDJSON().Parse(`true`))
+	if len(errs) != 1 || errs[0].Path != "" || errs[0].Code != V_ERR_NO_MATCH || errs[0].Error() != "/: no_match (expected [INT STRING], got bool)" {
+		t.Fatal(errs)
+	}
+
+	dv = NewValidator()
+	dv.Compile(`{"type": "OBJECT", "object": {"a": "INT"}}`)
+	errs = dv.Validate(NewDJSON().Parse(`[1]`))
+	if len(errs) != 1 || errs[0].Code != V_ERR_TYPE || errs[0].Actual != "array" {
+		t.Fatal(errs)
+	}
+}
+
+func TestValidateOptionalContainer(t *testing.T) {
+	dv := NewValidator()
+	dv.Compile(`{
+		"type": "OBJECT",
+		"object": {
+			"o": {"type": "OBJECT", "object": {"a": "INT"}},
+			"a": {"type": "ARRAY", "array": "INT"}
+		}
+	}`)
+
+	for _, s := range []string{
+		`{"o": null}`,
+		`{"o": "str"}`,
+		`{"a": null}`,
+		`{"a": 5}`,
+	} {
+		doc := NewDJSON().Parse(s)
+		if !dv.IsValid(doc) || dv.Validate(doc) != nil {
+			t.Fatal(s)
+		}
+	}
+
+	dv = NewValidator()
+	dv.Compile(`{
+		"type": "OBJECT",
+		"object": {
+			"o": {"type": "OBJECT", "object": {"a": "INT"}, "required": true},
+			"a": {"type": "ARRAY", "array": "INT", "required": true}
+		}
+	}`)
+
+	errs := dv.Validate(NewDJSON().Parse(`{"o": null, "a": 5}`))
+	if len(errs) != 2 || errs[0].Code != V_ERR_TYPE || errs[1].Code != V_ERR_TYPE {
+		t.Fatal(errs)
+	}
+}
@@ -0,0 +1,143 @@
+package djson
+
+import (
+	"fmt"
+	"sync"
+)
+
+// VType is a custom type for the Validator syntax. Type is the base type,
+// one of V_TYPE_STRING, V_TYPE_INT, V_TYPE_FLOAT or V_TYPE_NUMBER. Min and
+// Max bound the length of strings and the value of ints, MinFloat and
+// MaxFloat the value of floats and numbers. A pair left zero on both ends
+// cannot be told from an unset one and takes the bounds of the base type,
+// so a range of exactly zero has to be given in the schema with "min" and
+// "max" (or "size"), which override the bounds of the type. CheckFunc, if
+// set, checks strings as they are and ints in decimal, and gets Min and
+// Max; floats and numbers are checked by CheckFloatFunc instead, in decimal
+// with MinFloat and MaxFloat.
+
+type VType struct {
+	Type           int
+	Min            int64
+	Max            int64
+	MinFloat       float64
+	MaxFloat       float64
+	CheckFunc      func(string, ...int64) bool
+	CheckFloatFunc func(string, ...float64) bool
+}
+
+var builtinVTypes = map[string]bool{
+	"INT": true, "UNIXTIME": true, "UINT": true, "FLOAT": true, "NUMBER": true,
+	"STRING": true, "NONEMPTY.STRING": true, "MIN.MAX.STRING": true,
+	"OBJECT": true, "ARRAY": true, "NONEMPTY.ARRAY": true, "BOOL": true,
+	"BIN": true, "DEC": true, "HEX": true, "TIMESTAMP": true,
+	"YYYYMMDD": true, "YYMMDD": true, "HHMMSS": true, "HHMM": true,
+	"EMAIL": true, "UUID": true, "ISO31661A2": true, "ISO31662": true,
+	"BASE64": true, "TELEPHONE": true,
+	"INT.STRING": true, "INT_STRING": true, "FLOAT.STRING": true, "FLOAT_STRING": true,
+	"BOOL.STRING": true, "BOOL_STRING": true,
+	"HEX64.IF.EXIST": true, "HEX128.IF.EXIST": true, "HEX256.IF.EXIST": true,
+}
+
+var vtypeRegistry = struct {
+	sync.RWMutex
+	types map[string]*VType
+}{
+	types: make(map[string]*VType),
+}
+
+// RegisterType makes name usable as a type wherever the Validator syntax
+// takes one, and as a JSON Schema format if the base type is a string.
+// Registering a name again replaces it; the built-in names cannot be
+// replaced. Validators compiled before keep the type they were compiled
+// with.
+
+func RegisterType(name string, vt *VType) error {
+	if name == "" || builtinVTypes[name] {
+		return fmt.Errorf("%q: %w", name, InvalidTypeError)
+	}
+
+	if vt == nil {
+		return fmt.Errorf("%q: %w", name, InvalidTypeError)
+	}
+
+	switch vt.Type {
+	case V_TYPE_STRING, V_TYPE_INT:
+		if vt.CheckFloatFunc != nil {
+			return fmt.Errorf("%q: CheckFloatFunc on base type %d: %w", name, vt.Type, InvalidTypeError)
+		}
+	case V_TYPE_FLOAT, V_TYPE_NUMBER:
+		if vt.CheckFunc != nil {
+			return fmt.Errorf("%q: CheckFunc on base type %d: %w", name, vt.Type, InvalidTypeError)
+		}
+	default:
+		return fmt.Errorf("%q: base type %d: %w", name, vt.Type, InvalidTypeError)
+	}
+
+	t := *vt
+
+	vtypeRegistry.Lock()
+	vtypeRegistry.types[name] = &t
+	vtypeRegistry.Unlock()
+
+	return nil
+}
+
+func UnregisterType(name string) {
+	vtypeRegistry.Lock()
+	delete(vtypeRegistry.types, name)
+	vtypeRegistry.Unlock()
+}
+
+func lookupType(name string) (*VType, bool) {
+	vtypeRegistry.RLock()
+	defer vtypeRegistry.RUnlock()
+
+	vt, ok := vtypeRegistry.types[name]
+	return vt, ok
+}
+
+// apply sets up eitem for the type; ejson is the syntax of the item, an
+// object if the bounds may be overridden.
+
+func (vt *VType) apply(eitem *VItem, ejson *DJSON) {
+	eitem.Type = vt.Type
+	eitem.CheckFunc = vt.CheckFunc
+	eitem.CheckFloatFunc = vt.CheckFloatFunc
+
+	if vt.Type == V_TYPE_FLOAT || vt.Type == V_TYPE_NUMBER {
+		minFloat, maxFloat := vt.MinFloat, vt.MaxFloat
+		if minFloat == 0 && maxFloat == 0 {
+			minFloat, maxFloat = float64(-1.7976931348623157e+308), float64(1.7976931348623157e+308)
+		}
+
+		eitem.MinFloat = minFloat
+		eitem.MaxFloat = maxFloat
+		if ejson.IsObject() {
+			eitem.MinFloat = ejson.GetAsFloat("min", minFloat)
+			eitem.MaxFloat = ejson.GetAsFloat("max", maxFloat)
+		}
+		return
+	}
+
+	min, max := vt.Min, vt.Max
+	if min == 0 && max == 0 {
+		if vt.Type == V_TYPE_STRING {
+			max = 8192
+		} else {
+			min, max = int64(-9007199254740991), int64(9007199254740991)
+		}
+	}
+
+	eitem.Min = min
+	eitem.Max = max
+	if ejson.IsObject() {
+		if ejson.IsInt("size") {
+			eitem.Min = ejson.GetAsInt("size")
+			eitem.Max = eitem.Min
+		} else {
+			eitem.Min = ejson.GetAsInt("min", min)
+			eitem.Max = ejson.GetAsInt("max", max)
+		}
+	}
+}
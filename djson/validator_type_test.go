@@ -0,0 +1,165 @@
+package djson
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func checkBizNo(ts string, vi ...int64) bool {
+	ts = strings.ReplaceAll(ts, "-", "")
+	if len(ts) != 10 || !CheckFuncDec(strings.TrimLeft(ts, "0")+"0") {
+		return false
+	}
+
+	weights := []int{1, 3, 7, 1, 3, 7, 1, 3, 5}
+	sum := 0
+	for idx, w := range weights {
+		sum += int(ts[idx]-'0') * w
+	}
+	sum += int(ts[8]-'0') * 5 / 10
+
+	return (10-sum%10)%10 == int(ts[9]-'0')
+}
+
+func TestRegisterType(t *testing.T) {
+	if err := RegisterType("BIZNO", &VType{Type: V_TYPE_STRING, Min: 10, Max: 12, CheckFunc: checkBizNo}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterType("EVEN", &VType{Type: V_TYPE_INT, Min: 0, Max: 100, CheckFunc: func(s string, _ ...int64) bool {
+		return (s[len(s)-1]-'0')%2 == 0
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterType("RATIO", &VType{Type: V_TYPE_NUMBER, MinFloat: 0, MaxFloat: 1}); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterType("BIZNO")
+	defer UnregisterType("EVEN")
+	defer UnregisterType("RATIO")
+
+	dv := NewValidator()
+	dv.Compile(`{
+		"type": "OBJECT",
+		"object": {
+			"bizno": {"type": "BIZNO", "required": true},
+			"count": "EVEN",
+			"limit": {"type": "EVEN", "max": 10},
+			"ratio": "RATIO",
+			"others": {"type": "ARRAY", "array": ["BIZNO", "EVEN"]}
+		}
+	}`)
+
+	if !dv.IsValid(NewDJSON().Parse(`{"bizno": "220-81-62517", "count": 42, "limit": 10, "ratio": 0.5, "others": ["2208162517", 2]}`)) {
+		t.Fatal()
+	}
+
+	errs := dv.Validate(NewDJSON().Parse(`{"bizno": "220-81-62518", "count": 41, "limit": 12, "ratio": 1.5, "others": ["1", 3]}`))
+	expected := map[string]string{
+		"/bizno":    V_ERR_FORMAT,
+		"/count":    V_ERR_FORMAT,
+		"/limit":    V_ERR_TOO_LARGE,
+		"/ratio":    V_ERR_TOO_LARGE,
+		"/others/0": V_ERR_NO_MATCH,
+		"/others/1": V_ERR_NO_MATCH,
+	}
+	if len(errs) != len(expected) {
+		t.Fatal(errs)
+	}
+	for _, e := range errs {
+		if expected[e.Path] != e.Code {
+			t.Fatal(e.Error())
+		}
+		if e.Path == "/bizno" && e.Expected != "BIZNO" {
+			t.Fatal(e.Error())
+		}
+	}
+
+	schema, err := CompileSchema(NewDJSON().Parse(`{"type": "array", "items": {"type": "string", "format": "BIZNO"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !schema.IsValid(NewDJSON().Parse(`["220-81-62517"]`)) || schema.IsValid(NewDJSON().Parse(`["220-81-62518"]`)) {
+		t.Fatal()
+	}
+
+	UnregisterType("BIZNO")
+	if !dv.IsValid(NewDJSON().Parse(`{"bizno": "220-81-62517"}`)) {
+		t.Fatal()
+	}
+}
+
+func TestRegisterTypeInvalid(t *testing.T) {
+	for name, vt := range map[string]*VType{
+		"":      {Type: V_TYPE_STRING},
+		"EMAIL": {Type: V_TYPE_STRING},
+		"OBJ":   {Type: V_TYPE_OBJECT},
+		"NIL":   nil,
+		"FLT":   {Type: V_TYPE_FLOAT, CheckFunc: CheckFuncDec},
+		"STR":   {Type: V_TYPE_STRING, CheckFloatFunc: func(string, ...float64) bool { return true }},
+	} {
+		if err := RegisterType(name, vt); !errors.Is(err, InvalidTypeError) {
+			t.Fatal(name, err)
+		}
+	}
+}
+
+func TestRegisterTypeBounds(t *testing.T) {
+	var called bool
+	var bounds []int64
+	record := func(s string, vi ...int64) bool {
+		called, bounds = true, vi
+		return true
+	}
+
+	var floatBounds []float64
+	recordFloat := func(s string, vf ...float64) bool {
+		called, floatBounds = true, vf
+		return true
+	}
+
+	RegisterType("ANYINT", &VType{Type: V_TYPE_INT, CheckFunc: record})
+	if err := RegisterType("ANYFLOAT", &VType{Type: V_TYPE_FLOAT, MinFloat: -1, MaxFloat: 1, CheckFloatFunc: recordFloat}); err != nil {
+		t.Fatal(err)
+	}
+	defer UnregisterType("ANYINT")
+	defer UnregisterType("ANYFLOAT")
+
+	for _, tc := range []struct {
+		syntax string
+		doc    string
+		bounds []int64
+	}{
+		{`"ANYINT"`, `[-5]`, []int64{-9007199254740991, 9007199254740991}},
+		{`{"type": "ANYINT", "min": 0, "max": 0}`, `[0]`, []int64{0, 0}},
+	} {
+		called, bounds = false, nil
+
+		dv := NewValidator()
+		dv.Compile(`{"type": "ARRAY", "array": ` + tc.syntax + `}`)
+		if !dv.IsValid(NewDJSON().Parse(tc.doc)) || !called || len(bounds) != len(tc.bounds) {
+			t.Fatal(tc.syntax, bounds)
+		}
+		for idx := range bounds {
+			if bounds[idx] != tc.bounds[idx] {
+				t.Fatal(tc.syntax, bounds)
+			}
+		}
+	}
+
+	for _, tc := range []struct {
+		syntax string
+		bounds []float64
+	}{
+		{`"ANYFLOAT"`, []float64{-1, 1}},
+		{`{"type": "ANYFLOAT", "min": 0, "max": 0.5}`, []float64{0, 0.5}},
+	} {
+		called, floatBounds = false, nil
+
+		dv := NewValidator()
+		dv.Compile(`{"type": "ARRAY", "array": ` + tc.syntax + `}`)
+		if !dv.IsValid(NewDJSON().Parse(`[0.5]`)) || !called || len(floatBounds) != 2 || floatBounds[0] != tc.bounds[0] || floatBounds[1] != tc.bounds[1] {
+			t.Fatal(tc.syntax, floatBounds)
+		}
+	}
+}
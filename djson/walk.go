@@ -0,0 +1,148 @@
+package djson
+
+const (
+	WALK_CONTINUE = iota
+	WALK_SKIP     // do not visit the children of the node
+	WALK_STOP     // end the walk
+	WALK_REMOVE   // remove the node from its parent
+)
+
+// WalkNode is the node passed to a walk callback. Path holds the keys and
+// indexes from the root, Key its last token (nil at the root). Value shares
+// objects and arrays with the tree; change a node with Replace or
+// WALK_REMOVE, not through Value.
+
+type WalkNode struct {
+	Path  []interface{}
+	Key   interface{}
+	Depth int
+	Type  string
+	Value *DJSON
+
+	replaced    bool
+	replacement interface{}
+}
+
+func (n *WalkNode) PathString() string {
+	return BuildPath(n.Path...)
+}
+
+func (n *WalkNode) Pointer() string {
+	return BuildPointer(n.Path...)
+}
+
+// Replace sets a new value for the node once the callback returns. The
+// replacement is not walked into.
+
+func (n *WalkNode) Replace(v interface{}) {
+	n.replaced = true
+	n.replacement = v
+}
+
+type WalkFunc func(node *WalkNode) int
+
+type walker struct {
+	fn      WalkFunc
+	stopped bool
+}
+
+// Walk visits every node depth-first, parents before children, objects in
+// key order. Paths are those of the tree before the walk: removing an array
+// element does not shift the indexes of its later siblings. Removing the
+// root leaves m null.
+
+func (m *DJSON) Walk(fn WalkFunc) {
+	w := &walker{fn: fn}
+
+	node := &WalkNode{
+		Path:  []interface{}{},
+		Type:  m.GetType(),
+		Value: m,
+	}
+
+	action := w.fn(node)
+
+	switch {
+	case action == WALK_REMOVE:
+		*m = *newWalkRoot(m, nil)
+		return
+	case node.replaced:
+		*m = *newWalkRoot(m, node.replacement)
+		return
+	case action == WALK_STOP, action == WALK_SKIP:
+		return
+	}
+
+	w.walkElement(m.GetAsInterface(), node.Path)
+}
+
+func newWalkRoot(m *DJSON, v interface{}) *DJSON {
+	holder := NewObject().Put("", v)
+
+	ret, ok := elementToDJSON(holder.Map[""])
+	if !ok {
+		ret = NewDJSON()
+	}
+	ret.ordered = m.ordered
+	ret.lossless = m.lossless
+
+	return ret
+}
+
+func (w *walker) visit(path []interface{}, key interface{}, element interface{}) (*WalkNode, int) {
+	node := &WalkNode{
+		Path:  appendPath(path, key),
+		Key:   key,
+		Depth: len(path) + 1,
+	}
+	node.Value, _ = elementToDJSON(element)
+	node.Type = node.Value.GetType()
+
+	action := w.fn(node)
+	if action == WALK_STOP {
+		w.stopped = true
+	}
+
+	return node, action
+}
+
+func (w *walker) walkElement(element interface{}, path []interface{}) {
+	switch t := element.(type) {
+	case *DO:
+		for _, k := range t.Keys() {
+			node, action := w.visit(path, k, t.Map[k])
+
+			switch {
+			case action == WALK_REMOVE:
+				t.Remove(k)
+			case node.replaced:
+				t.Put(k, node.replacement)
+			case action == WALK_CONTINUE:
+				w.walkElement(t.Map[k], node.Path)
+			}
+
+			if w.stopped {
+				return
+			}
+		}
+	case *DA:
+		removed := make([]int, 0)
+
+		for idx := 0; idx < t.Size() && !w.stopped; idx++ {
+			node, action := w.visit(path, idx, t.Element[idx])
+
+			switch {
+			case action == WALK_REMOVE:
+				removed = append(removed, idx)
+			case node.replaced:
+				t.ReplaceAt(idx, node.replacement)
+			case action == WALK_CONTINUE:
+				w.walkElement(t.Element[idx], node.Path)
+			}
+		}
+
+		for idx := len(removed) - 1; idx >= 0; idx-- {
+			t.Remove(removed[idx])
+		}
+	}
+}
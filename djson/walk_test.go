@@ -0,0 +1,76 @@
+package djson
+
+import "testing"
+
+func TestWalk(t *testing.T) {
+	aJson := NewDJSON().Parse(`{"user":{"name":"kim","phone":"01012345678","tags":["a","b","c"]},"meta":{"skip":{"deep":1}},"z":1}`)
+
+	visited := make([]string, 0)
+	aJson.Walk(func(node *WalkNode) int {
+		visited = append(visited, node.Pointer()+":"+node.Type)
+
+		switch node.PathString() {
+		case `["meta"]`:
+			return WALK_SKIP
+		case `["user"]["phone"]`:
+			node.Replace("***")
+		case `["user"]["tags"][1]`:
+			return WALK_REMOVE
+		}
+
+		return WALK_CONTINUE
+	})
+
+	expected := []string{
+		":object", "/meta:object", "/user:object", "/user/name:string", "/user/phone:string",
+		"/user/tags:array", "/user/tags/0:string", "/user/tags/1:string", "/user/tags/2:string", "/z:int",
+	}
+	if len(visited) != len(expected) {
+		t.Fatal(visited)
+	}
+	for idx := range expected {
+		if visited[idx] != expected[idx] {
+			t.Fatal(visited)
+		}
+	}
+
+	if aJson.ToString() != `{"meta":{"skip":{"deep":1}},"user":{"name":"kim","phone":"***","tags":["a","c"]},"z":1}` {
+		t.Fatal(aJson.ToString())
+	}
+}
+
+func TestWalkStopAndRoot(t *testing.T) {
+	aJson := NewDJSON().Parse(`[1,[2,3],4]`)
+
+	count := 0
+	aJson.Walk(func(node *WalkNode) int {
+		count++
+		if node.Type == "int" && node.Value.GetAsInt() == 2 {
+			return WALK_STOP
+		}
+		return WALK_CONTINUE
+	})
+
+	if count != 4 {
+		t.Fatal(count)
+	}
+
+	aJson.Walk(func(node *WalkNode) int {
+		if node.Depth == 0 {
+			node.Replace(map[string]interface{}{"a": 1})
+		}
+		return WALK_CONTINUE
+	})
+
+	if aJson.ToString() != `{"a":1}` {
+		t.Fatal(aJson.ToString())
+	}
+
+	aJson.Walk(func(node *WalkNode) int {
+		return WALK_REMOVE
+	})
+
+	if !aJson.IsNull() {
+		t.Fatal(aJson.ToString())
+	}
+}
@@ -0,0 +1,313 @@
+package djson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAML documents load into ordered objects so that configuration keeps
+// the key order of the file when it is written back.
+
+const yamlMaxDepth = 1000
+
+// yamlMaxExpansion is how many values a document may produce beyond one
+// per byte, so that aliases to aliases cannot expand into billions of
+// values ("billion laughs").
+
+const yamlMaxExpansion = 100000
+
+var yamlErrorRegExp = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// lineOffset returns the byte offset of a 1-based line and rune column.
+
+func lineOffset(doc string, line int, column int) int64 {
+	offset := 0
+	for l := 1; l < line; l++ {
+		idx := strings.IndexByte(doc[offset:], '\n')
+		if idx < 0 {
+			return int64(len(doc))
+		}
+		offset += idx + 1
+	}
+
+	for c := 1; c < column && offset < len(doc) && doc[offset] != '\n'; c++ {
+		_, size := utf8.DecodeRuneInString(doc[offset:])
+		offset += size
+	}
+
+	return int64(offset)
+}
+
+// ParseYAML parses the first document of a YAML stream. On error m is left
+// unchanged and the error is a *ParseError carrying the line.
+
+func (m *DJSON) ParseYAML(doc string) (*DJSON, error) {
+	var root yaml.Node
+
+	if err := yaml.Unmarshal([]byte(doc), &root); err != nil {
+		line, msg := 1, err.Error()
+		if sm := yamlErrorRegExp.FindStringSubmatch(msg); sm != nil {
+			line, _ = strconv.Atoi(sm[1])
+			msg = sm[2]
+		}
+		return m, newParseErrorAt(doc, lineOffset(doc, line, 1), InvalidSyntaxError, msg)
+	}
+
+	var element interface{}
+
+	if len(root.Content) > 0 {
+		var err error
+		budget := len(doc) + yamlMaxExpansion
+		if element, err = yamlNodeToElement(doc, root.Content[0], 0, &budget); err != nil {
+			return m, err
+		}
+	}
+
+	ret, _ := elementToDJSON(element)
+	*m = *ret
+	return m, nil
+}
+
+func yamlNodeError(doc string, node *yaml.Node, msg string) error {
+	return newParseErrorAt(doc, lineOffset(doc, node.Line, node.Column), InvalidSyntaxError, msg)
+}
+
+// yamlSpend takes one from budget for each node converted or merged.
+
+func yamlSpend(doc string, node *yaml.Node, budget *int) error {
+	*budget--
+	if *budget < 0 {
+		return yamlNodeError(doc, node, "too many values, aliases expand too much")
+	}
+	return nil
+}
+
+func yamlNodeToElement(doc string, node *yaml.Node, depth int, budget *int) (interface{}, error) {
+	if depth > yamlMaxDepth {
+		return nil, yamlNodeError(doc, node, "nesting too deep")
+	}
+
+	if err := yamlSpend(doc, node, budget); err != nil {
+		return nil, err
+	}
+
+	switch node.Kind {
+	case yaml.AliasNode:
+		return yamlNodeToElement(doc, node.Alias, depth+1, budget)
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return yamlNodeToElement(doc, node.Content[0], depth+1, budget)
+	case yaml.SequenceNode:
+		arr := NewArray()
+		for _, each := range node.Content {
+			v, err := yamlNodeToElement(doc, each, depth+1, budget)
+			if err != nil {
+				return nil, err
+			}
+			arr.Element = append(arr.Element, v)
+		}
+		return arr, nil
+	case yaml.MappingNode:
+		obj := NewOrderedObject()
+		if err := yamlMergeMapping(doc, obj, node, false, depth, budget); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	}
+
+	return yamlScalar(doc, node)
+}
+
+// yamlMergeMapping puts the pairs of node into obj. Merged mappings (<<)
+// never override keys that are already set.
+
+func yamlMergeMapping(doc string, obj *DO, node *yaml.Node, merging bool, depth int, budget *int) error {
+	if depth > yamlMaxDepth {
+		return yamlNodeError(doc, node, "nesting too deep")
+	}
+
+	if merging {
+		if err := yamlSpend(doc, node, budget); err != nil {
+			return err
+		}
+	}
+
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	switch node.Kind {
+	case yaml.SequenceNode:
+		if !merging {
+			break
+		}
+		for _, each := range node.Content {
+			if err := yamlMergeMapping(doc, obj, each, true, depth+1, budget); err != nil {
+				return err
+			}
+		}
+		return nil
+	case yaml.MappingNode:
+		for idx := 0; idx+1 < len(node.Content); idx += 2 {
+			kNode, vNode := node.Content[idx], node.Content[idx+1]
+
+			if kNode.ShortTag() == "!!merge" {
+				if err := yamlMergeMapping(doc, obj, vNode, true, depth+1, budget); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if kNode.Kind != yaml.ScalarNode {
+				return yamlNodeError(doc, kNode, "mapping keys must be scalars")
+			}
+
+			if merging && obj.HasKey(kNode.Value) {
+				continue
+			}
+
+			v, err := yamlNodeToElement(doc, vNode, depth+1, budget)
+			if err != nil {
+				return err
+			}
+			obj.set(kNode.Value, v)
+		}
+		return nil
+	}
+
+	return yamlNodeError(doc, node, "merge value must be a mapping")
+}
+
+func yamlScalar(doc string, node *yaml.Node) (interface{}, error) {
+	switch node.ShortTag() {
+	case "!!null":
+		return nil, nil
+	case "!!bool":
+		var b bool
+		if err := node.Decode(&b); err != nil {
+			return nil, yamlNodeError(doc, node, err.Error())
+		}
+		return b, nil
+	case "!!int":
+		var i int64
+		if err := node.Decode(&i); err == nil {
+			return i, nil
+		}
+		b, ok := new(big.Int).SetString(strings.ReplaceAll(node.Value, "_", ""), 0)
+		if !ok {
+			return nil, yamlNodeError(doc, node, fmt.Sprintf("invalid integer %q", node.Value))
+		}
+		return json.Number(b.String()), nil
+	case "!!float":
+		// integers beyond 64 bits resolve as floats; keep their digits
+		if digits := json.Number(strings.TrimPrefix(strings.ReplaceAll(node.Value, "_", ""), "+")); isNumberLiteral(string(digits)) && isIntLiteral(digits) {
+			return digits, nil
+		}
+
+		var f float64
+		if err := node.Decode(&f); err != nil {
+			return nil, yamlNodeError(doc, node, err.Error())
+		}
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, yamlNodeError(doc, node, fmt.Sprintf("%s has no JSON representation", node.Value))
+		}
+		return f, nil
+	case "!!binary":
+		return strings.Join(strings.Fields(node.Value), ""), nil
+	}
+
+	return node.Value, nil
+}
+
+// ToYAML writes m as a block-style YAML document with two-space indent.
+
+func (m *DJSON) ToYAML() (string, error) {
+	node, err := elementToYAMLNode(m.GetAsInterface())
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(node); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func yamlScalarNode(tag string, value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+}
+
+func elementToYAMLNode(element interface{}) (*yaml.Node, error) {
+	switch t := element.(type) {
+	case nil:
+		return yamlScalarNode("!!null", "null"), nil
+	case bool:
+		return yamlScalarNode("!!bool", strconv.FormatBool(t)), nil
+	case string:
+		return yamlScalarNode("!!str", t), nil
+	case DO:
+		return elementToYAMLNode(&t)
+	case DA:
+		return elementToYAMLNode(&t)
+	case *DJSON:
+		return elementToYAMLNode(t.GetAsInterface())
+	case *DO:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, k := range t.Keys() {
+			v, err := elementToYAMLNode(t.Map[k])
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, yamlScalarNode("!!str", k), v)
+		}
+		return node, nil
+	case *DA:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for idx := range t.Element {
+			v, err := elementToYAMLNode(t.Element[idx])
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, v)
+		}
+		return node, nil
+	}
+
+	switch elementType(element) {
+	case JSON_INT:
+		s, _ := getStringBase(element)
+		return yamlScalarNode("!!int", s), nil
+	case JSON_FLOAT:
+		if n, ok := element.(json.Number); ok {
+			return yamlScalarNode("!!float", string(n)), nil
+		}
+		f, _ := getFloatBase(element)
+		s := strconv.FormatFloat(f, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".eEn") {
+			s += ".0"
+		}
+		return yamlScalarNode("!!float", s), nil
+	}
+
+	return nil, UnsupportedValueError
+}
@@ -0,0 +1,108 @@
+package djson
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+const yamlTestDoc = `# service configuration
+name: gateway
+port: 8080
+debug: false
+ratio: 0.75
+defaults: &defaults
+  timeout: 30
+  retries: 3
+upstreams:
+  - host: a.internal
+    <<: *defaults
+  - host: b.internal
+    timeout: 5
+    <<: *defaults
+tags: [edge, public]
+limit: 123456789012345678901234567890
+empty: ~
+`
+
+func TestParseYAML(t *testing.T) {
+	aJson, err := NewDJSON().ParseYAML(yamlTestDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if aJson.Object.Keys()[0] != "name" || aJson.Object.Keys()[6] != "tags" {
+		t.Fatal(aJson.Object.Keys())
+	}
+
+	if aJson.GetAsIntPath(`["upstreams"][0]["timeout"]`) != 30 || aJson.GetAsIntPath(`["upstreams"][1]["timeout"]`) != 5 {
+		t.Fatal(aJson.ToString())
+	}
+
+	if s, _ := aJson.GetAsDecimalString("limit"); s != "123456789012345678901234567890" {
+		t.Fatal(s)
+	}
+
+	if !aJson.HasKey("empty") || !aJson.IsNull("empty") {
+		t.Fatal(aJson.ToString())
+	}
+
+	out, err := aJson.ToYAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bJson, err := NewDJSON().ParseYAML(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bJson.ToString() != aJson.ToString() {
+		t.Fatalf("expected %s, got %s", aJson.ToString(), bJson.ToString())
+	}
+}
+
+func TestParseYAMLError(t *testing.T) {
+	aJson := NewDJSON().Parse(`{"a":1}`)
+
+	_, err := aJson.ParseYAML("a: 1\nb: c: d\n")
+
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Line != 2 {
+		t.Fatalf("expected parse error at line 2, got %v", err)
+	}
+
+	if aJson.ToString() != `{"a":1}` {
+		t.Fatal(aJson.ToString())
+	}
+
+	_, err = NewDJSON().ParseYAML("a: 1\nb: .nan\n")
+	if !errors.As(err, &perr) || perr.Line != 2 {
+		t.Fatalf("expected parse error at line 2, got %v", err)
+	}
+}
+
+func TestParseYAMLAliasBomb(t *testing.T) {
+	bomb := "a: &a [x, x, x, x, x, x, x, x, x, x]\n"
+	for idx, prev := 'b', 'a'; idx <= 'i'; idx, prev = idx+1, idx {
+		bomb += fmt.Sprintf("%c: &%c [*%c, *%c, *%c, *%c, *%c, *%c, *%c, *%c, *%c, *%c]\n", idx, idx, prev, prev, prev, prev, prev, prev, prev, prev, prev, prev)
+	}
+
+	var perr *ParseError
+	if _, err := NewDJSON().ParseYAML(bomb); !errors.As(err, &perr) || !errors.Is(err, InvalidSyntaxError) {
+		t.Fatal(err)
+	}
+
+	merge := "a: &a {k: 1}\n"
+	for idx, prev := 'b', 'a'; idx <= 'z'; idx, prev = idx+1, idx {
+		merge += fmt.Sprintf("%c: &%c {<<: [*%c, *%c, *%c, *%c]}\n", idx, idx, prev, prev, prev, prev)
+	}
+	if _, err := NewDJSON().ParseYAML(merge); !errors.As(err, &perr) {
+		t.Fatal(err)
+	}
+
+	aJson, err := NewDJSON().ParseYAML("base: &base {a: 1}\nx: *base\ny: {<<: *base, b: 2}\n")
+	if err != nil || aJson.ToString() != `{"base":{"a":1},"x":{"a":1},"y":{"a":1,"b":2}}` {
+		t.Fatal(err, aJson.ToString())
+	}
+}
@@ -15,6 +15,7 @@ require (
 	github.com/volatiletech/sqlboiler/v4 v4.16.2
 	golang.org/x/crypto v0.25.0
 	golang.org/x/sys v0.22.0
+	gopkg.in/yaml.v3 v3.0.1
 	lukechampine.com/blake3 v1.3.0
 	software.sslmate.com/src/go-pkcs12 v0.4.0
 )
@@ -38,5 +39,4 @@ require (
 	github.com/volatiletech/strmangle v0.0.6 // indirect
 	github.com/yusufpapurcu/wmi v1.2.4 // indirect
 	golang.org/x/xerrors v0.0.0-20240716161551-93cc26a95ae9 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -1,6 +1,7 @@
 package mt
 
 import (
+	"sync"
 	"unicode/utf8"
 )
 
@@ -17060,7 +17061,7 @@ var utf8cp949 = map[uint16]uint16{
 }
 
 func ToCP949(data []byte) []byte {
-	tr := make([]byte, 0)
+	tr := make([]byte, 0, len(data))
 	for i := 0; i < len(data); {
 		if data[i]&0x80 == 0 {
 			tr = append(tr, data[i])
@@ -17068,10 +17069,9 @@ func ToCP949(data []byte) []byte {
 			continue
 		}
 
-		r, s := utf8.DecodeRune(data[i : i+4])
-		utf8 := uint16(r)
+		r, s := utf8.DecodeRune(data[i:])
 
-		if cp949, ok := utf8cp949[utf8]; ok {
+		if cp949, ok := utf8cp949[uint16(r)]; ok && r <= 0xffff {
 			tr = append(tr, byte(cp949>>8), byte(cp949&0xff))
 		} else {
 			tr = append(tr, '?')
@@ -17082,3 +17082,39 @@ func ToCP949(data []byte) []byte {
 
 	return tr
 }
+
+var cp949utf8 map[uint16]uint16
+var cp949utf8Once sync.Once
+
+func FromCP949(data []byte) []byte {
+	cp949utf8Once.Do(func() {
+		cp949utf8 = make(map[uint16]uint16, len(utf8cp949))
+		for u, c := range utf8cp949 {
+			if prev, ok := cp949utf8[c]; !ok || u < prev {
+				cp949utf8[c] = u
+			}
+		}
+	})
+
+	tr := make([]byte, 0, len(data)*3/2)
+	for i := 0; i < len(data); {
+		if data[i]&0x80 == 0 {
+			tr = append(tr, data[i])
+			i++
+			continue
+		}
+
+		if i+1 < len(data) {
+			if u, ok := cp949utf8[uint16(data[i])<<8|uint16(data[i+1])]; ok {
+				tr = utf8.AppendRune(tr, rune(u))
+				i += 2
+				continue
+			}
+		}
+
+		tr = utf8.AppendRune(tr, utf8.RuneError)
+		i++
+	}
+
+	return tr
+}
@@ -102,6 +102,10 @@ func UTF8toEUCKR(s string) []byte {
 	return ToCP949([]byte(s))
 }
 
+func EUCKRtoUTF8(b []byte) string {
+	return string(FromCP949(b))
+}
+
 func EscapeSingle(s string) string {
 	return strings.ReplaceAll(s, "'", "\\'")
 }
@@ -16,4 +16,12 @@ func TestEUCKR(t *testing.T) {
 	fmt.Println(hex.EncodeToString(UTF8toEUCKR("아름다운 우리말")))
 
 	fmt.Println(hex.EncodeToString(UTF8toEUCKR("아름다운 हि리말")))
+
+	if string(UTF8toEUCKR("아름다운 우리말")) != "\xbe\xc6\xb8\xa7\xb4\xd9\xbf\xee \xbf\xec\xb8\xae\xb8\xbb" {
+		t.Fatal("unexpected CP949 encoding")
+	}
+
+	if EUCKRtoUTF8(UTF8toEUCKR("아름다운 우리말")) != "아름다운 우리말" {
+		t.Fatal("CP949 round trip failed")
+	}
 }